@@ -0,0 +1,187 @@
+package fpanels
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// AlertKind is the kind of an altitude alert
+type AlertKind int
+
+// Altitude alert kinds
+const (
+	// AlertApproaching is sent when the aircraft comes within the alert
+	// band, by default 1000 ft, of the selected altitude
+	AlertApproaching AlertKind = iota
+	// AlertCaptured is sent when the aircraft comes within the capture
+	// band, by default 200 ft, of the selected altitude
+	AlertCaptured
+	// AlertDeviation is sent when the aircraft leaves the capture band
+	// after the selected altitude has been captured
+	AlertDeviation
+)
+
+// AltitudeAlert is sent by the altitude alerter
+type AltitudeAlert struct {
+	Kind     AlertKind
+	Altitude int
+	Selected int
+}
+
+// alerterBlink is the blink of the ALT LED when alerting
+const alerterBlink = 300 * time.Millisecond
+
+// AltitudeAlerter alerts when the aircraft approaches and deviates from the
+// selected altitude. Feed it the aircraft altitude with SetAltitude, and
+// the selected altitude with SetSelected or by connecting it to an
+// Autopilot:
+//   ap := fpanels.NewAutopilot(panel)
+//   alerter := fpanels.NewAltitudeAlerter(panel)
+//   ap.SetAlerter(alerter)
+//   ...
+//   alerter.SetAltitude(altitude)
+// The ALT LED blinks while the aircraft is approaching the selected
+// altitude, and after it has deviated from the captured altitude. The LED
+// is blinked with an Animation, so it blinks even if the ALT mode is not
+// engaged. An AltitudeAlert is sent on every change. Changing the selected
+// altitude starts over.
+type AltitudeAlerter struct {
+	panel    *MultiPanel
+	mutex    sync.Mutex
+	alert    int
+	capture  int
+	selected int
+	altitude int
+	known    bool
+	state    alerterState
+	blink    AnimationID
+	blinking bool
+	eventCh  chan AltitudeAlert
+}
+
+// alerterState is the state of the altitude alerter
+type alerterState int
+
+const (
+	alerterIdle alerterState = iota
+	alerterApproaching
+	alerterCaptured
+	alerterDeviated
+)
+
+// NewAltitudeAlerter creates a new altitude alerter for the multi panel
+// with a 1000 ft alert band and a 200 ft capture band
+func NewAltitudeAlerter(panel *MultiPanel) *AltitudeAlerter {
+	return &AltitudeAlerter{
+		panel:   panel,
+		alert:   1000,
+		capture: 200,
+		eventCh: make(chan AltitudeAlert, 16),
+	}
+}
+
+// String returns the name of the alert kind, for example "Captured"
+func (k AlertKind) String() string {
+	switch k {
+	case AlertApproaching:
+		return "Approaching"
+	case AlertCaptured:
+		return "Captured"
+	case AlertDeviation:
+		return "Deviation"
+	}
+	return fmt.Sprintf("AlertKind(%d)", int(k))
+}
+
+// EventCh returns a channel for altitude alerts
+func (a *AltitudeAlerter) EventCh() chan AltitudeAlert {
+	return a.eventCh
+}
+
+// SetBands sets the alert and capture bands in ft
+func (a *AltitudeAlerter) SetBands(alert, capture int) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	a.alert = alert
+	a.capture = capture
+	a.update()
+}
+
+// SetSelected sets the selected altitude in ft. A new selected altitude
+// has not been captured.
+func (a *AltitudeAlerter) SetSelected(ft int) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	if ft == a.selected {
+		return
+	}
+	a.selected = ft
+	a.state = alerterIdle
+	a.setBlinking(false)
+	a.update()
+}
+
+// SetAltitude sets the current altitude of the aircraft in ft
+func (a *AltitudeAlerter) SetAltitude(ft int) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	a.altitude = ft
+	a.known = true
+	a.update()
+}
+
+// update moves to the next state for the current altitudes. The alerter
+// mutex must be held.
+func (a *AltitudeAlerter) update() {
+	if !a.known {
+		return
+	}
+	d := a.altitude - a.selected
+	if d < 0 {
+		d = -d
+	}
+	state := a.state
+	switch {
+	case d <= a.capture:
+		state = alerterCaptured
+	case a.state == alerterCaptured || a.state == alerterDeviated:
+		state = alerterDeviated
+	case d <= a.alert:
+		state = alerterApproaching
+	default:
+		state = alerterIdle
+	}
+	if state == a.state {
+		return
+	}
+	a.state = state
+	a.setBlinking(state == alerterApproaching || state == alerterDeviated)
+	kind := AlertApproaching
+	switch state {
+	case alerterIdle:
+		return
+	case alerterCaptured:
+		kind = AlertCaptured
+	case alerterDeviated:
+		kind = AlertDeviation
+	}
+	select {
+	case a.eventCh <- AltitudeAlert{kind, a.altitude, a.selected}:
+	default:
+	}
+}
+
+// setBlinking starts or stops blinking the ALT LED. The alerter mutex must
+// be held.
+func (a *AltitudeAlerter) setBlinking(on bool) {
+	if on == a.blinking {
+		return
+	}
+	a.blinking = on
+	if !on {
+		a.panel.StopAnimation(a.blink)
+		return
+	}
+	a.blink = a.panel.Animate(Alternate(alerterBlink, LEDALT, 0))
+}
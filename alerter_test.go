@@ -0,0 +1,68 @@
+package fpanels
+
+import "testing"
+
+// nextAlert returns the next alert of a, or false if there is none
+func nextAlert(a *AltitudeAlerter) (AltitudeAlert, bool) {
+	select {
+	case e := <-a.EventCh():
+		return e, true
+	default:
+		return AltitudeAlert{}, false
+	}
+}
+
+func TestAltitudeAlerter(t *testing.T) {
+	panel := newTestMultiPanel()
+	a := NewAltitudeAlerter(panel)
+	a.SetSelected(5000)
+	steps := []struct {
+		altitude int
+		event    bool
+		kind     AlertKind
+		blinking bool
+	}{
+		{2000, false, 0, false},
+		{4100, true, AlertApproaching, true},
+		{4500, false, 0, true},
+		{4850, true, AlertCaptured, false},
+		{5150, false, 0, false},
+		{5300, true, AlertDeviation, true},
+		{6500, false, 0, true},
+		{5100, true, AlertCaptured, false},
+	}
+	for _, s := range steps {
+		a.SetAltitude(s.altitude)
+		e, ok := nextAlert(a)
+		if ok != s.event || (ok && e != AltitudeAlert{s.kind, s.altitude, 5000}) {
+			t.Errorf("Altitude %d: alert %+v, %v, want %v, %v", s.altitude, e, ok, s.kind, s.event)
+		}
+		if blinking := len(panel.animations) == 1; blinking != s.blinking {
+			t.Errorf("Altitude %d: blinking %v, want %v", s.altitude, blinking, s.blinking)
+		}
+	}
+
+	a.SetSelected(8000)
+	a.SetBands(1000, 100)
+	a.SetAltitude(7050)
+	if e, _ := nextAlert(a); e != (AltitudeAlert{AlertApproaching, 7050, 8000}) {
+		t.Errorf("New selected altitude: alert %+v", e)
+	}
+	a.SetAltitude(7850)
+	if _, ok := nextAlert(a); ok || len(panel.animations) != 1 {
+		t.Errorf("Captured outside the capture band")
+	}
+}
+
+func TestAutopilotAlerter(t *testing.T) {
+	panel := newTestMultiPanel()
+	ap := NewAutopilot(panel)
+	ap.SetValue(RotALT, 3000)
+	a := NewAltitudeAlerter(panel)
+	ap.SetAlerter(a)
+	panel.setSwitch(EncCW, true)
+	a.SetAltitude(3050)
+	if e, _ := nextAlert(a); e != (AltitudeAlert{AlertCaptured, 3050, 3100}) {
+		t.Errorf("Alert %+v, want captured at 3100", e)
+	}
+}
@@ -0,0 +1,165 @@
+package fpanels
+
+import (
+	"time"
+)
+
+// Keyframe is one step of an LED animation. The LEDs given by LEDs are on
+// and all other LEDs controlled by the animation are off for Duration.
+type Keyframe struct {
+	LEDs     byte
+	Duration time.Duration
+}
+
+// Animation is a sequence of LED keyframes. The animation controls the LEDs
+// given by Mask, or all LEDs if Mask is zero. When several animations
+// control the same LED, then the animation with the highest Priority is
+// shown. LEDs not controlled by any animation show their steady state set by
+// the LEDs* functions. When an animation has finished the LEDs return to
+// their steady state.
+type Animation struct {
+	Frames   []Keyframe
+	Mask     byte
+	Priority int
+	// Repeat is the number of times the frames are played. Zero plays the
+	// frames until the animation is stopped.
+	Repeat int
+}
+
+// AnimationID identifies a running animation
+type AnimationID int
+
+// animation is a running animation
+type animation struct {
+	id    AnimationID
+	index int
+	start time.Time
+	Animation
+}
+
+// Chase returns an animation that turns on the LEDs given by leds one at a
+// time, starting with the lowest bit. Each LED is on for step. For example
+//   panel.Animate(Chase(LEDAllGreen, 200*time.Millisecond))
+func Chase(leds byte, step time.Duration) Animation {
+	a := Animation{Mask: leds}
+	for bit := byte(1); bit != 0; bit <<= 1 {
+		if leds&bit != 0 {
+			a.Frames = append(a.Frames, Keyframe{bit, step})
+		}
+	}
+	return a
+}
+
+// Fill returns an animation that turns on the LEDs given by leds one by one,
+// starting with the lowest bit, until all are on. It then starts over with
+// all LEDs off. Each step lasts for step.
+func Fill(leds byte, step time.Duration) Animation {
+	a := Animation{Mask: leds}
+	a.Frames = append(a.Frames, Keyframe{0, step})
+	on := byte(0)
+	for bit := byte(1); bit != 0; bit <<= 1 {
+		if leds&bit != 0 {
+			on |= bit
+			a.Frames = append(a.Frames, Keyframe{on, step})
+		}
+	}
+	return a
+}
+
+// Alternate returns an animation that alternates between the LED states
+// given by leds, each shown for step. For example, to cycle the landing
+// gear LEDs through red, green and yellow:
+//   panel.Animate(Alternate(time.Second, LEDAllRed, LEDAllGreen, LEDAllYellow))
+func Alternate(step time.Duration, leds ...byte) Animation {
+	a := Animation{}
+	for _, l := range leds {
+		a.Mask |= l
+		a.Frames = append(a.Frames, Keyframe{l, step})
+	}
+	return a
+}
+
+// at returns the LEDs shown by the animation at the time t since it was
+// started, and the time until the next keyframe. done is true if the
+// animation has finished.
+func (a *Animation) at(t time.Duration) (leds byte, next time.Duration, done bool) {
+	var cycle time.Duration
+	for _, f := range a.Frames {
+		cycle += f.Duration
+	}
+	if cycle <= 0 || (a.Repeat > 0 && t >= cycle*time.Duration(a.Repeat)) {
+		return 0, 0, true
+	}
+	t %= cycle
+	for _, f := range a.Frames {
+		if t < f.Duration {
+			return f.LEDs, f.Duration - t, false
+		}
+		t -= f.Duration
+	}
+	return 0, 0, true
+}
+
+// animate starts the animation a on the LEDs in the display state byte
+// index
+func (panel *panel) animate(index int, a Animation) AnimationID {
+	if a.Mask == 0 {
+		a.Mask = 0xff
+	}
+	panel.displayMutex.Lock()
+	defer panel.displayMutex.Unlock()
+	panel.lastAnimation++
+	anim := animation{panel.lastAnimation, index, time.Now(), a}
+	// Keep the animations sorted by priority, with the latest last
+	i := len(panel.animations)
+	for i > 0 && panel.animations[i-1].Priority > a.Priority {
+		i--
+	}
+	panel.animations = append(panel.animations, animation{})
+	copy(panel.animations[i+1:], panel.animations[i:])
+	panel.animations[i] = anim
+	panel.changed()
+	return anim.id
+}
+
+// stopAnimation stops the animation with the given id
+func (panel *panel) stopAnimation(id AnimationID) {
+	panel.displayMutex.Lock()
+	defer panel.displayMutex.Unlock()
+	for i, a := range panel.animations {
+		if a.id == id {
+			panel.animations = append(panel.animations[:i], panel.animations[i+1:]...)
+			panel.changed()
+			return
+		}
+	}
+}
+
+// applyAnimations sets the LEDs in buf controlled by the running animations
+// at the time now and removes finished animations. It returns the time
+// until the next keyframe, or 0 if no animations are running. The display
+// mutex must be held.
+func (panel *panel) applyAnimations(buf []byte, now time.Time) time.Duration {
+	var next time.Duration
+	running := panel.animations[:0]
+	for _, a := range panel.animations {
+		leds, d, done := a.at(now.Sub(a.start))
+		if done {
+			continue
+		}
+		running = append(running, a)
+		buf[a.index] = buf[a.index]&^a.Mask | leds&a.Mask
+		next = earliest(next, d)
+	}
+	panel.animations = running
+	return next
+}
+
+// earliest returns the shortest of the durations a and b, where 0 means
+// never
+func earliest(a, b time.Duration) time.Duration {
+	if a == 0 || (b > 0 && b < a) {
+		return b
+	}
+	return a
+}
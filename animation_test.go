@@ -0,0 +1,85 @@
+package fpanels
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAnimationAt(t *testing.T) {
+	ms := time.Millisecond
+	blink := Alternate(100*ms, 0x01, 0x00)
+	once := blink
+	once.Repeat = 1
+	twice := blink
+	twice.Repeat = 2
+	tests := []struct {
+		name string
+		a    Animation
+		t    time.Duration
+		leds byte
+		next time.Duration
+		done bool
+	}{
+		{"start", blink, 0, 0x01, 100 * ms, false},
+		{"first frame", blink, 40 * ms, 0x01, 60 * ms, false},
+		{"second frame", blink, 100 * ms, 0x00, 100 * ms, false},
+		{"second cycle", blink, 250 * ms, 0x01, 50 * ms, false},
+		{"forever", blink, time.Hour + 10*ms, 0x01, 90 * ms, false},
+		{"once", once, 150 * ms, 0x00, 50 * ms, false},
+		{"once done", once, 200 * ms, 0, 0, true},
+		{"twice", twice, 350 * ms, 0x00, 50 * ms, false},
+		{"twice done", twice, 400 * ms, 0, 0, true},
+		{"chase", Chase(0x07, 10*ms), 25 * ms, 0x04, 5 * ms, false},
+		{"fill", Fill(0x05, 10*ms), 25 * ms, 0x05, 5 * ms, false},
+		{"no frames", Animation{}, 0, 0, 0, true},
+		{"zero duration", Alternate(0, 0x01), 0, 0, 0, true},
+	}
+	for _, tt := range tests {
+		leds, next, done := tt.a.at(tt.t)
+		if leds != tt.leds || next != tt.next || done != tt.done {
+			t.Errorf("%s: at(%v) = %#x, %v, %v, want %#x, %v, %v", tt.name, tt.t, leds, next, done, tt.leds, tt.next, tt.done)
+		}
+	}
+}
+
+func TestEarliest(t *testing.T) {
+	tests := []struct {
+		a, b, want time.Duration
+	}{
+		{0, 0, 0},
+		{0, 5, 5},
+		{5, 0, 5},
+		{3, 5, 3},
+		{5, 3, 3},
+	}
+	for _, tt := range tests {
+		if got := earliest(tt.a, tt.b); got != tt.want {
+			t.Errorf("earliest(%v, %v) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestAnimateFrame(t *testing.T) {
+	ms := time.Millisecond
+	panel := newTestMultiPanel()
+	panel.LEDs(LEDHDG)
+	a := Chase(LEDAP|LEDNAV, 100*ms)
+	a.Repeat = 1
+	id := panel.Animate(a)
+	if f, _ := panel.render(50 * ms); f[10] != LEDAP|LEDHDG {
+		t.Errorf("First keyframe LEDs = %#x, want %#x", f[10], LEDAP|LEDHDG)
+	}
+	if f, _ := panel.render(150 * ms); f[10] != LEDNAV|LEDHDG {
+		t.Errorf("Second keyframe LEDs = %#x, want %#x", f[10], LEDNAV|LEDHDG)
+	}
+	if f, next := panel.render(time.Second); f[10] != LEDHDG || next != 0 || len(panel.animations) != 0 {
+		t.Errorf("Finished animation LEDs = %#x, %v, want %#x, 0", f[10], next, LEDHDG)
+	}
+
+	a.Repeat = 0
+	id = panel.Animate(a)
+	panel.StopAnimation(id)
+	if f, _ := panel.render(50 * ms); f[10] != LEDHDG {
+		t.Errorf("Stopped animation LEDs = %#x, want %#x", f[10], LEDHDG)
+	}
+}
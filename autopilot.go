@@ -0,0 +1,274 @@
+package fpanels
+
+import (
+	"errors"
+	"sync"
+)
+
+// ErrInvalidStep is returned when an encoder step size is not positive
+var ErrInvalidStep = errors.New("Invalid step size")
+
+// AutopilotEvent is sent when a selected value or an autopilot mode has
+// been changed with the multi panel
+type AutopilotEvent struct {
+	// Switch is the selector position of a changed value, i.e. RotALT,
+	// RotVS, RotIAS, RotHDG or RotCRS, the button that toggled a mode, i.e.
+	// BtnAP-BtnREV, or AutoThrottle
+	Switch SwitchID
+	// Value is the selected value, or 1 if the auto throttle is armed
+	Value int
+	// Engaged are the engaged modes as LED* bits
+	Engaged byte
+	// Armed are the armed modes as LED* bits
+	Armed byte
+}
+
+// apLimits are the bounds of a selected value. Values outside the bounds
+// are clamped, or wrapped around if wrap is true.
+type apLimits struct {
+	min, max int
+	wrap     bool
+}
+
+// autopilotLimits are the bounds of the values, indexed by selector
+// position
+var autopilotLimits = [5]apLimits{
+	{0, 99900, false},
+	{-9900, 9900, false},
+	{0, 999, false},
+	{0, 359, true},
+	{0, 359, true},
+}
+
+// Autopilot keeps the autopilot state shown on the multi panel: the
+// selected altitude, vertical speed, airspeed, heading and course, and the
+// engaged autopilot modes.
+//
+// The encoder changes the value of the selected position by its step size,
+// by default 100 ft for ALT, 100 ft/min for VS and 1 for IAS, HDG and CRS.
+// Heading and course wrap around between 359 and 0, the other values stop
+// at their limits. The displays follow the selector: in the ALT and VS
+// positions the altitude is shown on Row1 and the vertical speed on Row2.
+//
+// The buttons engage and disengage the autopilot modes, which are shown on
+// the button LEDs. BtnAP toggles LEDAP, BtnHDG toggles LEDHDG and so on.
+// Modes can also be armed with SetArmed, for example by a simulator, and
+// are then shown blinking, see MultiPanel.SetLEDState. Pressing the button
+// of an armed mode disarms it. An AutopilotEvent is sent on every change.
+type Autopilot struct {
+	panel   *MultiPanel
+	mutex   sync.Mutex
+	mode    SwitchID
+	values  [5]int
+	steps   [5]int
+	engaged byte
+	armed   byte
+	alerter *AltitudeAlerter
+	eventCh chan AutopilotEvent
+}
+
+// NewAutopilot creates a new autopilot for the multi panel and starts
+// handling its switch events
+func NewAutopilot(panel *MultiPanel) *Autopilot {
+	ap := &Autopilot{
+		panel:   panel,
+		mode:    panel.Mode(),
+		steps:   [5]int{100, 100, 1, 1, 1},
+		eventCh: make(chan AutopilotEvent, 16),
+	}
+	ap.paint()
+	panel.AddSwitchHandler(ap)
+	return ap
+}
+
+// EventCh returns a channel for autopilot events
+func (ap *Autopilot) EventCh() chan AutopilotEvent {
+	return ap.eventCh
+}
+
+// Value returns the selected value of the selector position mode, i.e.
+// RotALT, RotVS, RotIAS, RotHDG or RotCRS
+func (ap *Autopilot) Value(mode SwitchID) int {
+	ap.mutex.Lock()
+	defer ap.mutex.Unlock()
+	if mode < RotALT || mode > RotCRS {
+		return 0
+	}
+	return ap.values[mode]
+}
+
+// SetValue sets the selected value of the selector position mode, for
+// example when it has been changed by a simulator. The value is clamped or
+// wrapped to the limits of the value. ErrUnknownMode is returned if mode is
+// not a selector position.
+func (ap *Autopilot) SetValue(mode SwitchID, v int) error {
+	if mode < RotALT || mode > RotCRS {
+		return ErrUnknownMode
+	}
+	ap.mutex.Lock()
+	defer ap.mutex.Unlock()
+	ap.values[mode] = autopilotLimits[mode].limit(v)
+	ap.paint()
+	ap.updateAlerter()
+	return nil
+}
+
+// SetStep sets the step size of the encoder for the selector position mode.
+// ErrUnknownMode is returned if mode is not a selector position, and
+// ErrInvalidStep if step is not positive.
+func (ap *Autopilot) SetStep(mode SwitchID, step int) error {
+	if mode < RotALT || mode > RotCRS {
+		return ErrUnknownMode
+	}
+	if step <= 0 {
+		return ErrInvalidStep
+	}
+	ap.mutex.Lock()
+	defer ap.mutex.Unlock()
+	ap.steps[mode] = step
+	return nil
+}
+
+// Engaged returns the engaged autopilot modes as LED* bits
+func (ap *Autopilot) Engaged() byte {
+	ap.mutex.Lock()
+	defer ap.mutex.Unlock()
+	return ap.engaged
+}
+
+// SetEngaged sets the engaged autopilot modes, for example when they have
+// been changed by a simulator. See the LED* constants.
+func (ap *Autopilot) SetEngaged(leds byte) {
+	ap.mutex.Lock()
+	defer ap.mutex.Unlock()
+	ap.engaged = leds
+	ap.armed &^= leds
+	ap.paintLEDs()
+}
+
+// Armed returns the armed autopilot modes as LED* bits
+func (ap *Autopilot) Armed() byte {
+	ap.mutex.Lock()
+	defer ap.mutex.Unlock()
+	return ap.armed
+}
+
+// SetArmed sets the armed autopilot modes, for example when they have been
+// changed by a simulator. Modes that are armed are no longer engaged. See
+// the LED* constants.
+func (ap *Autopilot) SetArmed(leds byte) {
+	ap.mutex.Lock()
+	defer ap.mutex.Unlock()
+	ap.armed = leds
+	ap.engaged &^= leds
+	ap.paintLEDs()
+}
+
+// SetAlerter connects the altitude alerter to the autopilot. The selected
+// altitude of the alerter follows the ALT value. A nil alerter disconnects
+// the alerter.
+func (ap *Autopilot) SetAlerter(a *AltitudeAlerter) {
+	ap.mutex.Lock()
+	defer ap.mutex.Unlock()
+	ap.alerter = a
+	ap.updateAlerter()
+}
+
+// AutoThrottleArmed returns true if the auto throttle switch is in the ARM
+// position
+func (ap *Autopilot) AutoThrottleArmed() bool {
+	return ap.panel.IsSwitchSet(AutoThrottle)
+}
+
+// HandleSwitch handles the multi panel switch event s. It is called by the
+// panel, see AddSwitchHandler.
+func (ap *Autopilot) HandleSwitch(s SwitchState) {
+	ap.mutex.Lock()
+	defer ap.mutex.Unlock()
+	switch {
+	case s.Switch >= RotALT && s.Switch <= RotCRS:
+		if s.On {
+			ap.mode = s.Switch
+			ap.paint()
+		}
+	case s.Switch == EncCW || s.Switch == EncCCW:
+		if !s.On {
+			return
+		}
+		step := ap.steps[ap.mode]
+		if s.Switch == EncCCW {
+			step = -step
+		}
+		ap.values[ap.mode] = autopilotLimits[ap.mode].limit(ap.values[ap.mode] + step)
+		ap.paint()
+		ap.updateAlerter()
+		ap.send(AutopilotEvent{ap.mode, ap.values[ap.mode], ap.engaged, ap.armed})
+	case s.Switch >= BtnAP && s.Switch <= BtnREV:
+		if !s.On {
+			return
+		}
+		bit := byte(1) << uint(s.Switch-BtnAP)
+		if ap.armed&bit != 0 {
+			ap.armed &^= bit
+		} else {
+			ap.engaged ^= bit
+		}
+		ap.paintLEDs()
+		ap.send(AutopilotEvent{s.Switch, 0, ap.engaged, ap.armed})
+	case s.Switch == AutoThrottle:
+		armed := 0
+		if s.On {
+			armed = 1
+		}
+		ap.send(AutopilotEvent{s.Switch, armed, ap.engaged, ap.armed})
+	}
+}
+
+// paint shows the values of the selected position on the displays. The
+// autopilot mutex must be held.
+func (ap *Autopilot) paint() {
+	switch ap.mode {
+	case RotALT, RotVS:
+		ap.panel.DisplayValueFormat(RotALT, ap.values[RotALT], Format{Overflow: OverflowSaturate})
+		ap.panel.DisplayValueFormat(RotVS, ap.values[RotVS], Format{Overflow: OverflowSaturate})
+	default:
+		ap.panel.DisplayValueFormat(ap.mode, ap.values[ap.mode], Format{ZeroPad: ap.mode != RotIAS})
+	}
+}
+
+// paintLEDs shows the engaged and armed modes on the button LEDs. The
+// autopilot mutex must be held.
+func (ap *Autopilot) paintLEDs() {
+	ap.panel.SetLEDState(ap.engaged, LEDEngaged)
+	ap.panel.SetLEDState(ap.armed, LEDArmed)
+	ap.panel.SetLEDState(^(ap.engaged | ap.armed), LEDOff)
+}
+
+// updateAlerter sets the selected altitude of the alerter. The autopilot
+// mutex must be held.
+func (ap *Autopilot) updateAlerter() {
+	if ap.alerter != nil {
+		ap.alerter.SetSelected(ap.values[RotALT])
+	}
+}
+
+// send sends the autopilot event e if there is room in the event channel
+func (ap *Autopilot) send(e AutopilotEvent) {
+	select {
+	case ap.eventCh <- e:
+	default:
+	}
+}
+
+// limit clamps or wraps v to the limits l
+func (l apLimits) limit(v int) int {
+	switch {
+	case l.wrap:
+		return wrap(v-l.min, l.max-l.min+1) + l.min
+	case v < l.min:
+		return l.min
+	case v > l.max:
+		return l.max
+	}
+	return v
+}
@@ -0,0 +1,117 @@
+package fpanels
+
+import "testing"
+
+func TestAPLimits(t *testing.T) {
+	tests := []struct {
+		mode SwitchID
+		v    int
+		want int
+	}{
+		{RotALT, -100, 0},
+		{RotALT, 100000, 99900},
+		{RotALT, 5000, 5000},
+		{RotVS, -10000, -9900},
+		{RotVS, 10000, 9900},
+		{RotIAS, 1000, 999},
+		{RotHDG, 360, 0},
+		{RotHDG, -1, 359},
+		{RotHDG, 725, 5},
+		{RotCRS, -361, 359},
+	}
+	for _, tt := range tests {
+		if got := autopilotLimits[tt.mode].limit(tt.v); got != tt.want {
+			t.Errorf("Limit of %d for mode %d = %d, want %d", tt.v, tt.mode, got, tt.want)
+		}
+	}
+}
+
+func TestAutopilot(t *testing.T) {
+	panel := newTestMultiPanel()
+	ap := NewAutopilot(panel)
+	next := func() (AutopilotEvent, bool) {
+		select {
+		case e := <-ap.EventCh():
+			return e, true
+		default:
+			return AutopilotEvent{}, false
+		}
+	}
+	steps := []struct {
+		name  string
+		do    func()
+		event bool
+		want  AutopilotEvent
+	}{
+		{"ALT up", func() { panel.setSwitch(EncCW, true) }, true, AutopilotEvent{RotALT, 100, 0, 0}},
+		{"encoder off", func() { panel.setSwitch(EncCW, false) }, false, AutopilotEvent{}},
+		{"ALT clamped", func() {
+			panel.setSwitch(EncCCW, true)
+			next()
+			panel.setSwitch(EncCCW, true)
+		}, true, AutopilotEvent{RotALT, 0, 0, 0}},
+		{"ALT clamped high", func() {
+			ap.SetValue(RotALT, 99850)
+			panel.setSwitch(EncCW, true)
+			next()
+			panel.setSwitch(EncCW, true)
+		}, true, AutopilotEvent{RotALT, 99900, 0, 0}},
+		{"VS down", func() {
+			panel.setSwitch(RotALT, false)
+			panel.setSwitch(RotVS, true)
+			ap.SetValue(RotVS, -9850)
+			panel.setSwitch(EncCCW, true)
+		}, true, AutopilotEvent{RotVS, -9900, 0, 0}},
+		{"HDG wrapped", func() {
+			panel.setSwitch(RotVS, false)
+			panel.setSwitch(RotHDG, true)
+			panel.setSwitch(EncCCW, true)
+		}, true, AutopilotEvent{RotHDG, 359, 0, 0}},
+		{"HDG wrapped up", func() { panel.setSwitch(EncCW, true) }, true, AutopilotEvent{RotHDG, 0, 0, 0}},
+		{"HDG back", func() { panel.setSwitch(EncCCW, true) }, true, AutopilotEvent{RotHDG, 359, 0, 0}},
+		{"HDG step", func() {
+			ap.SetStep(RotHDG, 10)
+			panel.setSwitch(EncCW, true)
+		}, true, AutopilotEvent{RotHDG, 9, 0, 0}},
+		{"AP engaged", func() { panel.setSwitch(BtnAP, true) }, true, AutopilotEvent{BtnAP, 0, LEDAP, 0}},
+		{"button released", func() { panel.setSwitch(BtnAP, false) }, false, AutopilotEvent{}},
+		{"HDG engaged", func() { panel.setSwitch(BtnHDG, true) }, true, AutopilotEvent{BtnHDG, 0, LEDAP | LEDHDG, 0}},
+		{"AP disengaged", func() { panel.setSwitch(BtnAP, true) }, true, AutopilotEvent{BtnAP, 0, LEDHDG, 0}},
+		{"APR armed", func() { ap.SetArmed(LEDAPR) }, false, AutopilotEvent{}},
+		{"APR disarmed", func() { panel.setSwitch(BtnAPR, true) }, true, AutopilotEvent{BtnAPR, 0, LEDHDG, 0}},
+		{"NAV armed", func() { ap.SetArmed(LEDNAV) }, false, AutopilotEvent{}},
+		{"NAV engaged", func() {
+			ap.SetArmed(0)
+			panel.setSwitch(BtnNAV, true)
+		}, true, AutopilotEvent{BtnNAV, 0, LEDHDG | LEDNAV, 0}},
+		{"NAV disengaged", func() { panel.setSwitch(BtnNAV, true) }, true, AutopilotEvent{BtnNAV, 0, LEDHDG, 0}},
+		{"auto throttle", func() { panel.setSwitch(AutoThrottle, true) }, true, AutopilotEvent{AutoThrottle, 1, LEDHDG, 0}},
+	}
+	for _, s := range steps {
+		s.do()
+		e, ok := next()
+		if ok != s.event || e != s.want {
+			t.Errorf("%s: event %+v, %v, want %+v, %v", s.name, e, ok, s.want, s.event)
+		}
+	}
+	if ap.Value(RotHDG) != 9 || ap.Value(RotVS) != -9900 || ap.Engaged() != LEDHDG || !ap.AutoThrottleArmed() {
+		t.Errorf("State = %d, %d, %#x, %v", ap.Value(RotHDG), ap.Value(RotVS), ap.Engaged(), ap.AutoThrottleArmed())
+	}
+	if panel.displayState[10] != LEDHDG {
+		t.Errorf("LEDs = %#x, want %#x", panel.displayState[10], LEDHDG)
+	}
+	if g := glyphsString(panel.Digits(Row1)); g != "| | |0|0|9|" {
+		t.Errorf("Row 1 = %s, want | | |0|0|9|", g)
+	}
+	for _, step := range []int{0, -10} {
+		if err := ap.SetStep(RotHDG, step); err != ErrInvalidStep {
+			t.Errorf("SetStep(RotHDG, %d) = %v, want %v", step, err, ErrInvalidStep)
+		}
+	}
+	if err := ap.SetStep(BtnAP, 1); err != ErrUnknownMode {
+		t.Errorf("SetStep(BtnAP) = %v, want %v", err, ErrUnknownMode)
+	}
+	if err := ap.SetValue(BtnAP, 1); err != ErrUnknownMode {
+		t.Errorf("SetValue(BtnAP) = %v, want %v", err, ErrUnknownMode)
+	}
+}
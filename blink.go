@@ -0,0 +1,137 @@
+package fpanels
+
+import (
+	"time"
+)
+
+// Blink is a blink pattern for digits and LEDs. The digit or LED is shown
+// for DutyCycle of every Period and is off for the rest of the period. A
+// zero DutyCycle is the same as 0.5. All blinks on a panel with the same
+// pattern are in phase.
+type Blink struct {
+	Period    time.Duration
+	DutyCycle float64
+}
+
+// DefaultBlink blinks once per second
+var DefaultBlink = Blink{Period: time.Second, DutyCycle: 0.5}
+
+// NoBlink turns blinking off
+var NoBlink = Blink{}
+
+// blinker is a blink attribute on a display state byte
+type blinker struct {
+	index int
+	// mask is the bits of the byte that are turned off in the off phase,
+	// 0xff for digits
+	mask  byte
+	blink Blink
+}
+
+// at returns whether the blink is in the on phase at the time t since the
+// panel epoch, and the time until the phase changes
+func (b Blink) at(t time.Duration) (on bool, next time.Duration) {
+	duty := b.DutyCycle
+	if duty == 0 {
+		duty = 0.5
+	}
+	if duty >= 1 {
+		return true, 0
+	}
+	phase := t % b.Period
+	onTime := time.Duration(float64(b.Period) * duty)
+	if phase < onTime {
+		return true, onTime - phase
+	}
+	return false, b.Period - phase
+}
+
+// setBlink sets the blink attribute b on the bits mask of the display state
+// byte index. Blink attributes of other bits are left intact.
+func (panel *panel) setBlink(index int, mask byte, b Blink) {
+	panel.displayMutex.Lock()
+	defer panel.displayMutex.Unlock()
+	panel.writeBlink(index, mask, b)
+}
+
+// writeBlink sets the blink attribute b on the bits mask of the display
+// state byte index. Nothing changes if the bits already have the blink. The
+// display mutex must be held.
+func (panel *panel) writeBlink(index int, mask byte, b Blink) {
+	if panel.hasBlink(index, mask, b) {
+		return
+	}
+	blinks := panel.blinks[:0]
+	for _, bl := range panel.blinks {
+		if bl.index == index {
+			bl.mask &^= mask
+		}
+		if bl.mask != 0 {
+			blinks = append(blinks, bl)
+		}
+	}
+	if b.Period > 0 {
+		blinks = append(blinks, blinker{index, mask, b})
+	}
+	panel.blinks = blinks
+	panel.changed()
+}
+
+// hasBlink returns true if all the bits mask of the display state byte
+// index have the blink attribute b. The display mutex must be held.
+func (panel *panel) hasBlink(index int, mask byte, b Blink) bool {
+	var set byte
+	for _, bl := range panel.blinks {
+		if bl.index != index || bl.mask&mask == 0 {
+			continue
+		}
+		if bl.blink != b {
+			return false
+		}
+		set |= bl.mask & mask
+	}
+	if b.Period > 0 {
+		return set == mask
+	}
+	return set == 0
+}
+
+// applyBlinks turns off the bytes and bits of buf that are in the off phase
+// at the time now. It returns the time until the next phase change, or 0 if
+// nothing blinks. The display mutex must be held.
+func (panel *panel) applyBlinks(buf []byte, now time.Time) time.Duration {
+	var next time.Duration
+	t := now.Sub(panel.epoch)
+	for _, bl := range panel.blinks {
+		on, d := bl.blink.at(t)
+		if !on {
+			buf[bl.index] = buf[bl.index]&^bl.mask | panel.blankFrame[bl.index]&bl.mask
+		}
+		next = earliest(next, d)
+	}
+	return next
+}
+
+// wake makes the display refresher render a new frame
+func (panel *panel) wake() {
+	panel.displayMutex.Lock()
+	panel.setDirty()
+	panel.displayMutex.Unlock()
+}
+
+// scheduleWake makes the display refresher render a new frame after d. If d
+// is 0 then any scheduled wake up is cancelled. The display mutex must be
+// held.
+func (panel *panel) scheduleWake(d time.Duration) {
+	if d <= 0 {
+		if panel.wakeTimer != nil {
+			panel.wakeTimer.Stop()
+		}
+		return
+	}
+	if panel.wakeTimer == nil {
+		panel.wakeTimer = time.AfterFunc(d, panel.wake)
+		return
+	}
+	panel.wakeTimer.Reset(d)
+}
@@ -0,0 +1,70 @@
+package fpanels
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBlinkAt(t *testing.T) {
+	ms := time.Millisecond
+	tests := []struct {
+		b    Blink
+		t    time.Duration
+		on   bool
+		next time.Duration
+	}{
+		{DefaultBlink, 0, true, 500 * ms},
+		{DefaultBlink, 200 * ms, true, 300 * ms},
+		{DefaultBlink, 600 * ms, false, 400 * ms},
+		{DefaultBlink, 2100 * ms, true, 400 * ms},
+		{Blink{Period: time.Second}, 600 * ms, false, 400 * ms},
+		{Blink{Period: time.Second, DutyCycle: 0.25}, 300 * ms, false, 700 * ms},
+		{Blink{Period: time.Second, DutyCycle: 1}, 600 * ms, true, 0},
+	}
+	for _, tt := range tests {
+		on, next := tt.b.at(tt.t)
+		if on != tt.on || next != tt.next {
+			t.Errorf("%+v at(%v) = %v, %v, want %v, %v", tt.b, tt.t, on, next, tt.on, tt.next)
+		}
+	}
+}
+
+func TestBlinkFrame(t *testing.T) {
+	ms := time.Millisecond
+	panel := newTestMultiPanel()
+	panel.DisplayString(Row1, "12345")
+	panel.LEDs(LEDAP | LEDHDG)
+	if err := panel.BlinkDigit(Row1, 0, DefaultBlink); err != nil {
+		t.Fatal(err)
+	}
+	panel.BlinkLEDs(LEDAP, DefaultBlink)
+
+	f, next := panel.render(100 * ms)
+	if f[0] != 1 || f[10] != LEDAP|LEDHDG || next != 400*ms {
+		t.Errorf("On phase = %x, %v, want digit 1, LEDs %#x, %v", f, next, LEDAP|LEDHDG, 400*ms)
+	}
+	f, next = panel.render(600 * ms)
+	if f[0] != blank || f[1] != 2 || f[10] != LEDHDG || next != 400*ms {
+		t.Errorf("Off phase = %x, %v, want blank digit, LEDs %#x, %v", f, next, LEDHDG, 400*ms)
+	}
+
+	panel.BlinkDigit(Row1, 0, NoBlink)
+	panel.BlinkLEDs(LEDAP, NoBlink)
+	f, next = panel.render(600 * ms)
+	if f[0] != 1 || f[10] != LEDAP|LEDHDG || next != 0 {
+		t.Errorf("Stopped blinking = %x, %v, want digit 1, LEDs %#x, 0", f, next, LEDAP|LEDHDG)
+	}
+	if len(panel.blinks) != 0 {
+		t.Errorf("Blinks left after NoBlink: %+v", panel.blinks)
+	}
+}
+
+func TestBlinkDigitErrors(t *testing.T) {
+	panel := newTestMultiPanel()
+	if err := panel.BlinkDigit(Row1, 5, DefaultBlink); err != ErrInvalidPosition {
+		t.Errorf("BlinkDigit(Row1, 5) = %v, want %v", err, ErrInvalidPosition)
+	}
+	if err := panel.BlinkDisplay(DisplayID(3), DefaultBlink); err != ErrUnknownDisplay {
+		t.Errorf("BlinkDisplay(3) = %v, want %v", err, ErrUnknownDisplay)
+	}
+}
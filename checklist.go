@@ -0,0 +1,312 @@
+package fpanels
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+)
+
+// ChecklistItem is a switch panel switch that should be on or off
+type ChecklistItem struct {
+	Name   string
+	Switch SwitchID
+	On     bool
+}
+
+// Checklist is a named list of switch positions, for example
+//   Before start: BAT on, AVIONICS off, BEACON on
+type Checklist struct {
+	Name  string
+	Items []ChecklistItem
+}
+
+// ChecklistEvent is sent when the items that are out of position have
+// changed
+type ChecklistEvent struct {
+	Checklist string
+	// Pending are the items that are out of position
+	Pending []ChecklistItem
+	Done    bool
+}
+
+// checklistSwitches are the switch panel switches that can be used in
+// checklists, see SwitchIDMap
+var checklistSwitches = []string{
+	"BAT", "ALTERNATOR", "AVIONICS", "FUEL", "DEICE", "PITOT", "COWL",
+	"PANEL", "BEACON", "NAV", "STROBE", "TAXI", "LANDING", "ENG_OFF",
+	"ALT_R", "ALT_L", "ALT_BOTH", "ENG_START", "GEAR_UP", "GEAR_DOWN",
+}
+
+// checklistAliases are the names printed on the switch panel that differ
+// from the names in SwitchIDMap
+var checklistAliases = map[string]string{
+	"ALT":    "ALTERNATOR",
+	"DE-ICE": "DEICE",
+}
+
+// ParseChecklists reads checklists from r, one checklist per line in the
+// format
+//   Name: SWITCH on|off, SWITCH on|off, ...
+// The switch names are the switch panel names of SwitchIDMap, or ALT and
+// DE-ICE as printed on the panel. Names and states are case insensitive.
+// Empty lines and lines starting with # are ignored.
+func ParseChecklists(r io.Reader) ([]Checklist, error) {
+	var lists []Checklist
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		l, err := parseChecklist(line)
+		if err != nil {
+			return nil, fmt.Errorf("Line %d: %v", n, err)
+		}
+		lists = append(lists, l)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return lists, nil
+}
+
+// LoadChecklists reads checklists from the file path, see ParseChecklists
+func LoadChecklists(path string) ([]Checklist, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ParseChecklists(f)
+}
+
+// parseChecklist parses a single checklist line
+func parseChecklist(line string) (Checklist, error) {
+	colon := strings.Index(line, ":")
+	if colon < 0 {
+		return Checklist{}, errors.New("Missing ':' after checklist name")
+	}
+	l := Checklist{Name: strings.TrimSpace(line[:colon])}
+	for _, item := range strings.Split(line[colon+1:], ",") {
+		fields := strings.Fields(item)
+		if len(fields) != 2 {
+			return Checklist{}, fmt.Errorf("Invalid item %q", strings.TrimSpace(item))
+		}
+		id, err := checklistSwitch(fields[0])
+		if err != nil {
+			return Checklist{}, fmt.Errorf("%v %q", err, fields[0])
+		}
+		var on bool
+		switch strings.ToLower(fields[1]) {
+		case "on":
+			on = true
+		case "off":
+		default:
+			return Checklist{}, fmt.Errorf("Invalid switch state %q", fields[1])
+		}
+		l.Items = append(l.Items, ChecklistItem{strings.ToUpper(fields[0]), id, on})
+	}
+	return l, nil
+}
+
+// checklistSwitch returns the switch panel switch with the given name
+func checklistSwitch(name string) (SwitchID, error) {
+	name = strings.ToUpper(name)
+	if alias, ok := checklistAliases[name]; ok {
+		name = alias
+	}
+	for _, s := range checklistSwitches {
+		if s == name {
+			return SwitchIDString(name)
+		}
+	}
+	return 0, errors.New("Unknown switch")
+}
+
+// Pending returns the items of the checklist that are out of position in
+// the switch state s
+func (l Checklist) Pending(s PanelSwitches) []ChecklistItem {
+	var pending []ChecklistItem
+	for _, item := range l.Items {
+		if s.IsSet(item.Switch) != item.On {
+			pending = append(pending, item)
+		}
+	}
+	return pending
+}
+
+// ChecklistRunner checks a checklist against the switch panel while the
+// switches are moved. A ChecklistEvent is sent when the checklist is
+// started and every time the pending items change.
+//
+// The progress can be shown on a radio panel display as the number of done
+// and total items, for example "3-5", or only the number of remaining items
+// when that does not fit the display, and on the gear LEDs as the share of
+// done items, from all red to all green. The progress is shown in display
+// layers named "checklist" that are removed by Stop.
+type ChecklistRunner struct {
+	panel    *SwitchPanel
+	mutex    sync.Mutex
+	list     Checklist
+	pending  []ChecklistItem
+	running  bool
+	radio    *RadioPanel
+	display  DisplayID
+	showGear bool
+	eventCh  chan ChecklistEvent
+}
+
+// NewChecklistRunner creates a new checklist runner for the switch panel
+// and starts handling its switch events
+func NewChecklistRunner(panel *SwitchPanel) *ChecklistRunner {
+	r := &ChecklistRunner{
+		panel:   panel,
+		eventCh: make(chan ChecklistEvent, 16),
+	}
+	panel.AddSwitchHandler(r)
+	return r
+}
+
+// EventCh returns a channel for checklist events
+func (r *ChecklistRunner) EventCh() chan ChecklistEvent {
+	return r.eventCh
+}
+
+// ShowOnRadio shows the progress on the given radio panel display. A nil
+// panel turns it off. ErrUnknownDisplay is returned if display is not a
+// radio panel display.
+func (r *ChecklistRunner) ShowOnRadio(panel *RadioPanel, display DisplayID) error {
+	if panel != nil && (display < Display1Active || display > Display2Standby) {
+		return ErrUnknownDisplay
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if r.radio != nil {
+		r.radio.RemoveLayer("checklist")
+	}
+	r.radio = panel
+	r.display = display
+	return r.show()
+}
+
+// ShowOnGear turns on or off showing the progress on the gear LEDs
+func (r *ChecklistRunner) ShowOnGear(on bool) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.showGear = on
+	if !on {
+		r.panel.RemoveLayer("checklist")
+	}
+	// The radio display has been checked by ShowOnRadio
+	r.show()
+}
+
+// Start starts checking the checklist l
+func (r *ChecklistRunner) Start(l Checklist) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.list = l
+	r.running = true
+	r.update(true)
+}
+
+// Stop stops checking and removes the progress from the displays
+func (r *ChecklistRunner) Stop() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.running = false
+	if r.radio != nil {
+		r.radio.RemoveLayer("checklist")
+	}
+	r.panel.RemoveLayer("checklist")
+}
+
+// Pending returns the items that are out of position
+func (r *ChecklistRunner) Pending() []ChecklistItem {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return append([]ChecklistItem(nil), r.pending...)
+}
+
+// HandleSwitch handles the switch panel switch event s. It is called by the
+// panel, see AddSwitchHandler.
+func (r *ChecklistRunner) HandleSwitch(s SwitchState) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.update(false)
+}
+
+// update checks the checklist against the switches, and sends an event if
+// the pending items have changed or if force is true. The runner mutex must
+// be held.
+func (r *ChecklistRunner) update(force bool) {
+	if !r.running {
+		return
+	}
+	pending := r.list.Pending(r.panel.Switches())
+	if !force && samePending(pending, r.pending) {
+		return
+	}
+	r.pending = pending
+	// The radio display has been checked by ShowOnRadio
+	r.show()
+	select {
+	case r.eventCh <- ChecklistEvent{r.list.Name, append([]ChecklistItem(nil), pending...), len(pending) == 0}:
+	default:
+	}
+}
+
+// show shows the progress. It returns the error from showing it on the
+// radio panel. The runner mutex must be held.
+func (r *ChecklistRunner) show() error {
+	if !r.running {
+		return nil
+	}
+	total := len(r.list.Items)
+	done := total - len(r.pending)
+	var err error
+	if r.radio != nil {
+		err = r.radio.LayerGlyphs("checklist", 0, 0, r.display, progressGlyphs(done, total))
+	}
+	if r.showGear {
+		var leds byte
+		for leg := 0; leg < 3; leg++ {
+			// Legs turn green at 1/3, 2/3 and all items done
+			if total == 0 || done*3 >= (leg+1)*total {
+				leds |= gearGreen[leg]
+			} else {
+				leds |= gearRed[leg]
+			}
+		}
+		r.panel.LayerLEDs("checklist", 0, 0, leds, LEDAll)
+	}
+	return err
+}
+
+// progressGlyphs returns the number of done and total items as five
+// glyphs, or only the number of remaining items if both do not fit
+func progressGlyphs(done, total int) []Glyph {
+	g := numberGlyphs(fmt.Sprintf("%d-%d", done, total))
+	if len(g) <= 5 {
+		return g
+	}
+	g, _ = FormatInt(total-done, 5, Format{Overflow: OverflowSaturate})
+	return g
+}
+
+// samePending returns true if a and b are the same items
+func samePending(a, b []ChecklistItem) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,144 @@
+package fpanels
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseChecklists(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []Checklist
+		err   string
+	}{
+		{
+			name:  "single",
+			input: "Before start: BAT on, AVIONICS off, BEACON on",
+			want: []Checklist{{"Before start", []ChecklistItem{
+				{"BAT", SwBat, true},
+				{"AVIONICS", SwAvionics, false},
+				{"BEACON", SwBeacon, true},
+			}}},
+		},
+		{
+			name:  "comments and case",
+			input: "# Comment\n\n  Taxi:taxi ON,  nav Off  \nShutdown: alt off, de-ice OFF\n",
+			want: []Checklist{
+				{"Taxi", []ChecklistItem{{"TAXI", SwTaxi, true}, {"NAV", SwNav, false}}},
+				{"Shutdown", []ChecklistItem{{"ALT", SwAlternator, false}, {"DE-ICE", SwDeice, false}}},
+			},
+		},
+		{
+			name:  "gear and magnetos",
+			input: "Landing: GEAR_DOWN on, ALT_BOTH on",
+			want: []Checklist{{"Landing", []ChecklistItem{
+				{"GEAR_DOWN", GearDown, true},
+				{"ALT_BOTH", RotBoth, true},
+			}}},
+		},
+		{name: "empty", input: "\n# only comments\n"},
+		{name: "missing colon", input: "BAT on", err: "Line 1: Missing ':' after checklist name"},
+		{name: "missing state", input: "Start: BAT", err: `Line 1: Invalid item "BAT"`},
+		{name: "empty item", input: "Start: BAT on,", err: `Line 1: Invalid item ""`},
+		{name: "unknown switch", input: "\nStart: FOO on", err: `Line 2: Unknown switch "FOO"`},
+		{name: "other panel", input: "Start: ALT_HOLD on", err: `Line 1: Unknown switch "ALT_HOLD"`},
+		{name: "bad state", input: "Start: BAT maybe", err: `Line 1: Invalid switch state "maybe"`},
+	}
+	for _, tt := range tests {
+		lists, err := ParseChecklists(strings.NewReader(tt.input))
+		if tt.err != "" {
+			if err == nil || err.Error() != tt.err {
+				t.Errorf("%s: error %v, want %q", tt.name, err, tt.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(lists, tt.want) {
+			t.Errorf("%s: got %+v, want %+v", tt.name, lists, tt.want)
+		}
+	}
+}
+
+func TestChecklistPending(t *testing.T) {
+	l := Checklist{"Before start", []ChecklistItem{
+		{"BAT", SwBat, true},
+		{"AVIONICS", SwAvionics, false},
+	}}
+	tests := []struct {
+		switches PanelSwitches
+		want     []string
+	}{
+		{0, []string{"BAT"}},
+		{1 << SwBat, nil},
+		{1<<SwBat | 1<<SwAvionics, []string{"AVIONICS"}},
+		{1 << SwAvionics, []string{"BAT", "AVIONICS"}},
+	}
+	for _, tt := range tests {
+		var names []string
+		for _, item := range l.Pending(tt.switches) {
+			names = append(names, item.Name)
+		}
+		if !reflect.DeepEqual(names, tt.want) {
+			t.Errorf("Pending(%#x) = %v, want %v", uint32(tt.switches), names, tt.want)
+		}
+	}
+}
+
+func TestProgressGlyphs(t *testing.T) {
+	tests := []struct {
+		done, total int
+		want        string
+	}{
+		{0, 3, "|0|-|3|"},
+		{3, 12, "|3|-|1|2|"},
+		{10, 99, "|1|0|-|9|9|"},
+		{10, 120, "| | |1|1|0|"},
+		{5, 100005, "|9|9|9|9|9|"},
+	}
+	for _, tt := range tests {
+		if s := glyphsString(progressGlyphs(tt.done, tt.total)); s != tt.want {
+			t.Errorf("progressGlyphs(%d, %d) = %s, want %s", tt.done, tt.total, s, tt.want)
+		}
+	}
+}
+
+func TestChecklistRunnerRadio(t *testing.T) {
+	panel := newTestSwitchPanel()
+	radio := newTestRadioPanel()
+	r := NewChecklistRunner(panel)
+	if err := r.ShowOnRadio(radio, Display2Standby+1); err != ErrUnknownDisplay {
+		t.Errorf("ShowOnRadio(4) = %v, want %v", err, ErrUnknownDisplay)
+	}
+	if err := r.ShowOnRadio(radio, Display2Active); err != nil {
+		t.Fatal(err)
+	}
+	shown := func() string {
+		f, _ := radio.render(0)
+		g := make([]Glyph, 5)
+		for i := range g {
+			g[i] = radioByteGlyph(f[10+i])
+		}
+		return glyphsString(g)
+	}
+	r.Start(Checklist{"Before start", []ChecklistItem{
+		{"BAT", SwBat, true},
+		{"AVIONICS", SwAvionics, false},
+		{"BEACON", SwBeacon, true},
+	}})
+	if s := shown(); s != "| | |1|-|3|" {
+		t.Errorf("Started progress = %s, want | | |1|-|3|", s)
+	}
+	panel.setSwitch(SwBat, true)
+	if s := shown(); s != "| | |2|-|3|" {
+		t.Errorf("Progress = %s, want | | |2|-|3|", s)
+	}
+	r.Stop()
+	if s := shown(); s != "| | | | | |" {
+		t.Errorf("Stopped progress = %s, want blank", s)
+	}
+}
@@ -0,0 +1,145 @@
+package fpanels
+
+import (
+	"time"
+)
+
+// DefaultDimRefreshRate is the default maximum number of frames per second
+// sent to a panel while LEDs are dimmed
+const DefaultDimRefreshRate = 200
+
+// DimmableLEDs returns an option that enables software dimming of the LEDs.
+// With dimming enabled, a value between 0 and 1 given to LEDsOnOff sets the
+// brightness of the LEDs. The LEDs are dimmed by turning them on and off
+// with up to DefaultDimRefreshRate frames per second, see DimRefreshRate.
+// The actual rate is reported by RefreshRate. The dimming is best effort and
+// may flicker.
+func DimmableLEDs() Option {
+	return func(panel *panel) {
+		panel.dimmable = true
+	}
+}
+
+// DimRefreshRate returns an option that sets the maximum number of frames
+// per second sent to the panel while LEDs are dimmed. A higher rate flickers
+// less but loads the USB bus and the CPU more. A rate of 0 or less selects
+// DefaultDimRefreshRate.
+func DimRefreshRate(rate float64) Option {
+	return func(panel *panel) {
+		panel.dimRate = rate
+	}
+}
+
+// frameInterval returns the minimum time between frames while the panel is
+// refreshed continuously
+func (panel *panel) frameInterval() time.Duration {
+	rate := panel.dimRate
+	if rate <= 0 {
+		rate = DefaultDimRefreshRate
+	}
+	return time.Duration(float64(time.Second) / rate)
+}
+
+// setLEDs sets the LEDs given by mask to the state given by leds at full
+// brightness. The LED states outside mask are left intact.
+func (panel *panel) setLEDs(leds byte, mask byte) {
+	panel.displayMutex.Lock()
+	panel.writeLEDs(leds, mask)
+	panel.displayMutex.Unlock()
+}
+
+// writeLEDs sets the LEDs given by mask to the state given by leds at full
+// brightness. The display mutex must be held.
+func (panel *panel) writeLEDs(leds byte, mask byte) {
+	state := panel.displayState[panel.ledIndex]&^mask | leds&mask
+	if state != panel.displayState[panel.ledIndex] || panel.dimmed&mask != 0 {
+		panel.displayState[panel.ledIndex] = state
+		panel.dimmed &^= mask
+		panel.changed()
+	}
+}
+
+// setLEDLevel sets the brightness of the LEDs given by leds to val. If
+// dimming is not enabled, then the LEDs are turned on for any val above 0.
+func (panel *panel) setLEDLevel(leds byte, val float64) {
+	panel.displayMutex.Lock()
+	panel.writeLEDLevel(leds, val)
+	panel.displayMutex.Unlock()
+}
+
+// writeLEDLevel sets the brightness of the LEDs given by leds to val. The
+// display mutex must be held.
+func (panel *panel) writeLEDLevel(leds byte, val float64) {
+	switch {
+	case val <= 0:
+		panel.writeLEDs(0, leds)
+		return
+	case val >= 1 || !panel.dimmable:
+		panel.writeLEDs(leds, leds)
+		return
+	}
+	same := panel.displayState[panel.ledIndex]&leds == leds && panel.dimmed&leds == leds
+	for i := uint(0); i < 8; i++ {
+		if leds&(1<<i) != 0 {
+			same = same && panel.ledLevels[i] == val
+			panel.ledLevels[i] = val
+		}
+	}
+	if !same {
+		panel.displayState[panel.ledIndex] |= leds
+		panel.dimmed |= leds
+		panel.changed()
+	}
+}
+
+// applyDimming turns off the dimmed LEDs in buf that should be off in this
+// frame. A LED with brightness 0.3 is on in three frames out of ten. It
+// returns true if any LEDs are dimmed. The display mutex must be held.
+func (panel *panel) applyDimming(buf []byte) bool {
+	if panel.dimmed == 0 {
+		return false
+	}
+	dimmed := panel.dimmed & buf[panel.ledIndex]
+	if dimmed == 0 {
+		return false
+	}
+	for i := uint(0); i < 8; i++ {
+		bit := byte(1) << i
+		if dimmed&bit == 0 {
+			continue
+		}
+		panel.ledAcc[i] += panel.ledLevels[i]
+		// Allow for rounding errors in the accumulated levels
+		if panel.ledAcc[i] >= 1-1e-9 {
+			panel.ledAcc[i]--
+		} else {
+			buf[panel.ledIndex] &^= bit
+		}
+	}
+	return true
+}
+
+// measureRate updates the measured refresh rate when a frame is rendered at
+// the time now. The display mutex must be held.
+func (panel *panel) measureRate(now time.Time) {
+	if panel.continuous && !panel.lastFrame.IsZero() {
+		interval := now.Sub(panel.lastFrame).Seconds()
+		if interval > 0 {
+			if panel.refreshRate == 0 {
+				panel.refreshRate = 1 / interval
+			} else {
+				panel.refreshRate = 0.9*panel.refreshRate + 0.1/interval
+			}
+		}
+	}
+	panel.lastFrame = now
+}
+
+// RefreshRate returns the measured number of frames per second sent to the
+// panel while it is refreshed continuously, for example when LEDs are
+// dimmed. The rate is 0 until the panel has been refreshed continuously.
+func (panel *panel) RefreshRate() float64 {
+	panel.displayMutex.Lock()
+	defer panel.displayMutex.Unlock()
+	return panel.refreshRate
+}
@@ -0,0 +1,65 @@
+package fpanels
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLEDDimming(t *testing.T) {
+	tests := []struct {
+		dimmable bool
+		level    float64
+		on       int
+	}{
+		{true, 0, 0},
+		{true, 0.1, 10},
+		{true, 0.3, 30},
+		{true, 0.5, 50},
+		{true, 0.75, 75},
+		{true, 1, 100},
+		{false, 0.3, 100},
+		{false, 0, 0},
+	}
+	for _, tt := range tests {
+		panel := newTestMultiPanel()
+		if tt.dimmable {
+			DimmableLEDs()(&panel.panel)
+		}
+		panel.LEDs(LEDHDG)
+		panel.LEDsOnOff(LEDAP, tt.level)
+		on := 0
+		for i := 0; i < 100; i++ {
+			f, _ := panel.render(0)
+			if f[10]&LEDAP != 0 {
+				on++
+			}
+			if f[10]&LEDHDG == 0 {
+				t.Fatalf("Level %v turned off another LED", tt.level)
+			}
+		}
+		if on != tt.on {
+			t.Errorf("Level %v (dimmable %v) on in %d of 100 frames, want %d", tt.level, tt.dimmable, on, tt.on)
+		}
+	}
+}
+
+func TestDimRefreshRate(t *testing.T) {
+	tests := []struct {
+		rate float64
+		want time.Duration
+	}{
+		{0, 5 * time.Millisecond},
+		{-10, 5 * time.Millisecond},
+		{50, 20 * time.Millisecond},
+		{1000, time.Millisecond},
+	}
+	for _, tt := range tests {
+		panel := newTestMultiPanel()
+		if tt.rate != 0 {
+			DimRefreshRate(tt.rate)(&panel.panel)
+		}
+		if got := panel.frameInterval(); got != tt.want {
+			t.Errorf("DimRefreshRate(%v) interval = %v, want %v", tt.rate, got, tt.want)
+		}
+	}
+}
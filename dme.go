@@ -0,0 +1,164 @@
+package fpanels
+
+import (
+	"sync"
+	"time"
+)
+
+// DMEField is a value shown by a DMEDisplay
+type DMEField int
+
+// DME fields
+const (
+	// DMEDistance is the distance in NM with one decimal, up to 999.9. It
+	// uses four glyphs.
+	DMEDistance DMEField = iota
+	// DMESpeed is the groundspeed in knots, up to 999. It uses three
+	// glyphs.
+	DMESpeed
+	// DMETime is the time to station in minutes, up to 99. It uses two
+	// glyphs.
+	DMETime
+)
+
+// DMELayout decides which fields a DMEDisplay shows on the active and
+// standby displays. The fields of a display are shown in the given order,
+// separated by a dot, and must fit in five glyphs. For example the layout
+//   DMELayout{
+//     Active:  []DMEField{DMEDistance},
+//     Standby: []DMEField{DMESpeed, DMETime},
+//   }
+// shows " 12.3" and "140.12" for 12.3 NM at 140 knots with 12 minutes to
+// the station.
+type DMELayout struct {
+	Active  []DMEField
+	Standby []DMEField
+}
+
+// DefaultDMELayout shows the distance on the active display, and the speed
+// and time on the standby display
+var DefaultDMELayout = DMELayout{
+	Active:  []DMEField{DMEDistance},
+	Standby: []DMEField{DMESpeed, DMETime},
+}
+
+// DMEDisplay is a RadioWidget that shows the distance, groundspeed and time
+// to station of a DME, for example in the DME position:
+//   c := fpanels.NewRadioController(panel)
+//   dme := fpanels.NewDMEDisplay(c.RadioMemory)
+//   c.SetWidget(fpanels.PosDME, dme)
+//   dme.Set(12.3, 140, 12)
+// The fields show dashes until the first call to Set, and after Clear.
+// Values that do not fit are shown as the largest value that fits. A
+// DMEDisplay is safe for concurrent use.
+type DMEDisplay struct {
+	memory   *RadioMemory
+	mutex    sync.Mutex
+	layout   DMELayout
+	valid    bool
+	distance float64
+	speed    int
+	minutes  int
+}
+
+// NewDMEDisplay creates a new DME display with the default layout. The
+// radio memory is repainted when the values change.
+func NewDMEDisplay(m *RadioMemory) *DMEDisplay {
+	return &DMEDisplay{memory: m, layout: DefaultDMELayout}
+}
+
+// SetLayout sets the layout of the display. ErrOverflow is returned, and
+// the layout is not changed, if the fields do not fit.
+func (d *DMEDisplay) SetLayout(l DMELayout) error {
+	if dmeWidth(l.Active) > 5 || dmeWidth(l.Standby) > 5 {
+		return ErrOverflow
+	}
+	d.mutex.Lock()
+	d.layout = l
+	d.mutex.Unlock()
+	d.memory.Repaint()
+	return nil
+}
+
+// Set sets the distance in NM, the groundspeed in knots and the time to
+// station in minutes
+func (d *DMEDisplay) Set(distance float64, speed, minutes int) {
+	d.mutex.Lock()
+	d.valid = true
+	d.distance = distance
+	d.speed = speed
+	d.minutes = minutes
+	d.mutex.Unlock()
+	d.memory.Repaint()
+}
+
+// Clear shows dashes in all fields, for example when the DME has no
+// station
+func (d *DMEDisplay) Clear() {
+	d.mutex.Lock()
+	d.valid = false
+	d.mutex.Unlock()
+	d.memory.Repaint()
+}
+
+// Input ignores all inputs. The DME is tuned with the NAV radios.
+func (d *DMEDisplay) Input(in RadioInput, now time.Time) bool {
+	return false
+}
+
+// Glyphs returns the glyphs of the active and standby displays
+func (d *DMEDisplay) Glyphs() (active, standby []Glyph) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	return d.fieldGlyphs(d.layout.Active), d.fieldGlyphs(d.layout.Standby)
+}
+
+// fieldGlyphs returns the fields right aligned on a five glyph display. The
+// DME mutex must be held.
+func (d *DMEDisplay) fieldGlyphs(fields []DMEField) []Glyph {
+	var g []Glyph
+	for i, field := range fields {
+		if i > 0 {
+			g[len(g)-1] = g[len(g)-1].WithDot()
+		}
+		g = append(g, d.field(field)...)
+	}
+	return Format{}.pad(g, 5)
+}
+
+// field returns the glyphs of a single field. The DME mutex must be held.
+func (d *DMEDisplay) field(field DMEField) []Glyph {
+	f := Format{Overflow: OverflowSaturate}
+	if !d.valid {
+		f.Overflow = OverflowDashes
+		g, _ := f.overflow(dmeWidth([]DMEField{field}))
+		return g
+	}
+	var g []Glyph
+	switch field {
+	case DMEDistance:
+		f.Decimals = 1
+		g, _ = FormatFloat(d.distance, 4, f)
+	case DMESpeed:
+		g, _ = FormatInt(d.speed, 3, f)
+	default:
+		g, _ = FormatInt(d.minutes, 2, f)
+	}
+	return g
+}
+
+// dmeWidth returns the number of glyphs used by the fields
+func dmeWidth(fields []DMEField) int {
+	width := 0
+	for _, field := range fields {
+		switch field {
+		case DMEDistance:
+			width += 4
+		case DMESpeed:
+			width += 3
+		default:
+			width += 2
+		}
+	}
+	return width
+}
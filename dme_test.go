@@ -0,0 +1,36 @@
+package fpanels
+
+import "testing"
+
+func TestDMEDisplay(t *testing.T) {
+	panel := newTestRadioPanel()
+	c := NewRadioController(panel)
+	dme := NewDMEDisplay(c.RadioMemory)
+	c.SetWidget(PosDME, dme)
+	panel.setSwitch(Rot2DME, true)
+	steps := []struct {
+		name            string
+		do              func()
+		active, standby string
+	}{
+		{"no station", func() {}, "| |-|-|-|-|", "|-|-|-|-|-|"},
+		{"set", func() { dme.Set(12.3, 140, 12) }, "| | |1|2.|3|", "|1|4|0.|1|2|"},
+		{"saturated", func() { dme.Set(1234.5, 1000, 120) }, "| |9|9|9.|9|", "|9|9|9.|9|9|"},
+		{"layout", func() {
+			dme.Set(5, 90, 3)
+			dme.SetLayout(DMELayout{Active: []DMEField{DMETime}, Standby: []DMEField{DMEDistance}})
+		}, "| | | | |3|", "| | | |5.|0|"},
+		{"cleared", func() { dme.Clear() }, "| | | |-|-|", "| |-|-|-|-|"},
+	}
+	for _, s := range steps {
+		s.do()
+		active := glyphsString(panel.Digits(Display2Active))
+		standby := glyphsString(panel.Digits(Display2Standby))
+		if active != s.active || standby != s.standby {
+			t.Errorf("%s: displays %s %s, want %s %s", s.name, active, standby, s.active, s.standby)
+		}
+	}
+	if err := dme.SetLayout(DMELayout{Active: []DMEField{DMEDistance, DMETime}}); err != ErrOverflow {
+		t.Errorf("SetLayout too wide = %v, want %v", err, ErrOverflow)
+	}
+}
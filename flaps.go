@@ -0,0 +1,146 @@
+package fpanels
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// FlapNotch is a flap lever position
+type FlapNotch struct {
+	Name    string
+	Degrees int
+}
+
+// FlapsEvent is sent when the flaps have been moved to a new notch
+type FlapsEvent struct {
+	// Index is the index of the notch in the notch table, 0 for flaps up
+	Index int
+	Notch FlapNotch
+}
+
+// ErrUnknownNotch is returned when a flap notch does not exist
+var ErrUnknownNotch = errors.New("Unknown flap notch")
+
+// DefaultFlapNotches are flaps with 0, 10, 20 and 30 degree notches
+var DefaultFlapNotches = []FlapNotch{
+	{"UP", 0},
+	{"10", 10},
+	{"20", 20},
+	{"FULL", 30},
+}
+
+// Flaps moves between the notches of a flap lever with the multi panel
+// flaps switch. FlapsDown extends the flaps one notch and FlapsUp retracts
+// them one notch. The flaps stop at the first and last notch. A FlapsEvent
+// is sent on every change.
+//
+// The degrees of the current notch can be shown on Row2 with SetDisplay.
+// The notch is shown in a display layer named "flaps", so it covers the
+// vertical speed while it is shown.
+type Flaps struct {
+	panel   *MultiPanel
+	mutex   sync.Mutex
+	notches []FlapNotch
+	index   int
+	display bool
+	timeout time.Duration
+	eventCh chan FlapsEvent
+}
+
+// NewFlaps creates new flaps with the given notches for the multi panel and
+// starts handling its flaps switch. The flaps are up. If notches is empty,
+// then DefaultFlapNotches are used.
+func NewFlaps(panel *MultiPanel, notches []FlapNotch) *Flaps {
+	if len(notches) == 0 {
+		notches = DefaultFlapNotches
+	}
+	f := &Flaps{
+		panel:   panel,
+		notches: append([]FlapNotch(nil), notches...),
+		eventCh: make(chan FlapsEvent, 16),
+	}
+	panel.AddSwitchHandler(f)
+	return f
+}
+
+// EventCh returns a channel for flaps events
+func (f *Flaps) EventCh() chan FlapsEvent {
+	return f.eventCh
+}
+
+// Index returns the index of the current notch
+func (f *Flaps) Index() int {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return f.index
+}
+
+// Notch returns the current notch
+func (f *Flaps) Notch() FlapNotch {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return f.notches[f.index]
+}
+
+// Set moves the flaps to the notch with the given index, for example when
+// they have been moved by a simulator. ErrUnknownNotch is returned if there
+// is no such notch.
+func (f *Flaps) Set(index int) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if index < 0 || index >= len(f.notches) {
+		return ErrUnknownNotch
+	}
+	f.index = index
+	f.show()
+	return nil
+}
+
+// SetDisplay turns on or off showing the degrees of the notch on Row2.
+// When the flaps are moved the degrees are shown for timeout, or until
+// they are turned off if timeout is 0.
+func (f *Flaps) SetDisplay(on bool, timeout time.Duration) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.display = on
+	f.timeout = timeout
+	if !on {
+		f.panel.RemoveLayer("flaps")
+		return
+	}
+	f.show()
+}
+
+// HandleSwitch handles the multi panel switch event s. It is called by the
+// panel, see AddSwitchHandler.
+func (f *Flaps) HandleSwitch(s SwitchState) {
+	if !s.On || (s.Switch != FlapsUp && s.Switch != FlapsDown) {
+		return
+	}
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	index := f.index + 1
+	if s.Switch == FlapsUp {
+		index = f.index - 1
+	}
+	if index < 0 || index >= len(f.notches) {
+		return
+	}
+	f.index = index
+	f.show()
+	select {
+	case f.eventCh <- FlapsEvent{index, f.notches[index]}:
+	default:
+	}
+}
+
+// show shows the degrees of the current notch on Row2 if the display is
+// turned on. The flaps mutex must be held.
+func (f *Flaps) show() {
+	if !f.display {
+		return
+	}
+	g, _ := FormatInt(f.notches[f.index].Degrees, 5, Format{})
+	f.panel.LayerGlyphs("flaps", 0, f.timeout, Row2, g)
+}
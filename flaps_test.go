@@ -0,0 +1,72 @@
+package fpanels
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFlaps(t *testing.T) {
+	panel := newTestMultiPanel()
+	f := NewFlaps(panel, nil)
+	f.SetDisplay(true, 0)
+	steps := []struct {
+		id    SwitchID
+		on    bool
+		event bool
+		index int
+		row2  string
+	}{
+		{FlapsUp, true, false, 0, "| | | | |0|"},
+		{FlapsDown, true, true, 1, "| | | |1|0|"},
+		{FlapsDown, false, false, 1, "| | | |1|0|"},
+		{FlapsDown, true, true, 2, "| | | |2|0|"},
+		{FlapsDown, true, true, 3, "| | | |3|0|"},
+		{FlapsDown, true, false, 3, "| | | |3|0|"},
+		{FlapsUp, true, true, 2, "| | | |2|0|"},
+	}
+	for _, s := range steps {
+		panel.setSwitch(s.id, s.on)
+		var e FlapsEvent
+		ok := false
+		select {
+		case e = <-f.EventCh():
+			ok = true
+		default:
+		}
+		if ok != s.event || (ok && e != FlapsEvent{s.index, DefaultFlapNotches[s.index]}) {
+			t.Errorf("Switch %d %v: event %+v, %v, want %d, %v", s.id, s.on, e, ok, s.index, s.event)
+		}
+		frame, _ := panel.render(0)
+		var row2 []Glyph
+		for _, b := range frame[5:10] {
+			row2 = append(row2, multiByteGlyph(b))
+		}
+		if f.Index() != s.index || glyphsString(row2) != s.row2 {
+			t.Errorf("Switch %d %v: notch %d, row 2 %s, want %d, %s", s.id, s.on, f.Index(), glyphsString(row2), s.index, s.row2)
+		}
+	}
+
+	f.SetDisplay(false, 0)
+	if len(panel.layers) != 0 {
+		t.Error("Flaps layer shown after SetDisplay(false)")
+	}
+	if err := f.Set(4); err != ErrUnknownNotch {
+		t.Errorf("Set(4) = %v, want %v", err, ErrUnknownNotch)
+	}
+	if err := f.Set(0); err != nil || f.Notch() != (FlapNotch{"UP", 0}) {
+		t.Errorf("Set(0) = %v, notch %+v", err, f.Notch())
+	}
+}
+
+func TestFlapsDisplayTimeout(t *testing.T) {
+	panel := newTestMultiPanel()
+	f := NewFlaps(panel, []FlapNotch{{"UP", 0}, {"1", 5}})
+	f.SetDisplay(true, time.Second)
+	panel.setSwitch(FlapsDown, true)
+	if frame, _ := panel.render(0); frame[9] != 5 {
+		t.Errorf("Row 2 = %x, want 5 in the last digit", frame[5:10])
+	}
+	if frame, _ := panel.render(time.Hour); frame[9] != blank {
+		t.Errorf("Row 2 after timeout = %x, want blank", frame[5:10])
+	}
+}
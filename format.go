@@ -0,0 +1,220 @@
+package fpanels
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Alignment is the horizontal alignment of a value on a display
+type Alignment int
+
+// Alignments
+const (
+	AlignRight Alignment = iota
+	AlignLeft
+	AlignCenter
+)
+
+// OverflowPolicy decides what to show when a value does not fit a display
+type OverflowPolicy int
+
+// Overflow policies
+const (
+	// OverflowTruncate shows as much as fits. Integers keep their leftmost
+	// digits, floats are first rounded to fewer decimals.
+	OverflowTruncate OverflowPolicy = iota
+	// OverflowSaturate shows the largest or smallest value that fits,
+	// for example 99999 or -9999
+	OverflowSaturate
+	// OverflowDashes fills the display with dashes
+	OverflowDashes
+	// OverflowError leaves the display intact and returns ErrOverflow
+	OverflowError
+)
+
+// ErrOverflow is returned when a value does not fit the display and the
+// OverflowError policy is used
+var ErrOverflow = errors.New("Value does not fit display")
+
+// ErrInvalidWidth is returned when a number is formatted with a negative
+// width
+var ErrInvalidWidth = errors.New("Invalid display width")
+
+// Format contains the options for formatting numbers on segment displays.
+// The zero value formats like DisplayInt, i.e. right aligned, blank filled
+// and truncated on overflow.
+type Format struct {
+	Align Alignment
+	// ZeroPad fills the display with leading zeros. Alignment is then
+	// ignored.
+	ZeroPad bool
+	// Decimals is the number of decimals shown by FormatFloat. The value
+	// is rounded to the number of decimals.
+	Decimals int
+	Overflow OverflowPolicy
+}
+
+// FormatInt formats the integer n as width glyphs according to the format f
+func FormatInt(n int, width int, f Format) ([]Glyph, error) {
+	if width < 0 {
+		return nil, ErrInvalidWidth
+	}
+	g := numberGlyphs(strconv.Itoa(n))
+	if len(g) <= width {
+		return f.pad(g, width), nil
+	}
+	switch f.Overflow {
+	case OverflowTruncate:
+		return g[:width], nil
+	case OverflowSaturate:
+		return f.pad(saturated(n < 0, width, 0), width), nil
+	}
+	return f.overflow(width)
+}
+
+// FormatFloat formats the floating point number x as width glyphs according
+// to the format f. The decimal point does not use a position of its own
+// since it is shown together with the preceding digit.
+func FormatFloat(x float64, width int, f Format) ([]Glyph, error) {
+	if width < 0 {
+		return nil, ErrInvalidWidth
+	}
+	decimals := f.Decimals
+	if decimals < 0 {
+		decimals = 0
+	}
+	if math.IsNaN(x) {
+		return f.overflow(width)
+	}
+	var g []Glyph
+	if !math.IsInf(x, 0) {
+		g = numberGlyphs(formatFloat(x, decimals))
+		if len(g) <= width {
+			return f.pad(g, width), nil
+		}
+	}
+	switch f.Overflow {
+	case OverflowTruncate:
+		if g != nil {
+			for d := decimals - 1; d >= 0; d-- {
+				g = numberGlyphs(formatFloat(x, d))
+				if len(g) <= width {
+					return f.pad(g, width), nil
+				}
+			}
+			return g[:width], nil
+		}
+		// infinity can only be saturated
+		fallthrough
+	case OverflowSaturate:
+		return f.pad(saturated(x < 0, width, decimals), width), nil
+	}
+	return f.overflow(width)
+}
+
+// formatFloat formats x with the given number of decimals without
+// showing negative zero
+func formatFloat(x float64, decimals int) string {
+	s := strconv.FormatFloat(x, 'f', decimals, 64)
+	if strings.Trim(s, "-0.") == "" {
+		s = strings.TrimPrefix(s, "-")
+	}
+	return s
+}
+
+// saturated returns the largest positive or smallest negative number that
+// fits width with the given number of decimals. Only the minus sign fits
+// a single negative glyph.
+func saturated(negative bool, width int, decimals int) []Glyph {
+	if width <= 0 {
+		return nil
+	}
+	if negative && width == 1 {
+		return []Glyph{GlyphDash}
+	}
+	digits := width
+	if negative {
+		digits--
+	}
+	if decimals >= digits {
+		decimals = digits - 1
+	}
+	s := strings.Repeat("9", digits-decimals)
+	if decimals > 0 {
+		s += "." + strings.Repeat("9", decimals)
+	}
+	if negative {
+		s = "-" + s
+	}
+	return numberGlyphs(s)
+}
+
+// overflow returns the dash or error overflow result
+func (f Format) overflow(width int) ([]Glyph, error) {
+	if f.Overflow == OverflowDashes {
+		g := make([]Glyph, width)
+		for i := range g {
+			g[i] = GlyphDash
+		}
+		return g, nil
+	}
+	return nil, ErrOverflow
+}
+
+// pad aligns the glyphs g within width glyphs
+func (f Format) pad(g []Glyph, width int) []Glyph {
+	fill := width - len(g)
+	if fill <= 0 {
+		return g
+	}
+	p := make([]Glyph, 0, width)
+	if f.ZeroPad {
+		if len(g) > 0 && g[0] == GlyphDash {
+			p = append(p, GlyphDash)
+			g = g[1:]
+		}
+		for i := 0; i < fill; i++ {
+			p = append(p, DigitGlyph(0))
+		}
+		return append(p, g...)
+	}
+	left := fill
+	switch f.Align {
+	case AlignLeft:
+		left = 0
+	case AlignCenter:
+		left = fill / 2
+	}
+	for i := 0; i < left; i++ {
+		p = append(p, GlyphBlank)
+	}
+	p = append(p, g...)
+	for len(p) < width {
+		p = append(p, GlyphBlank)
+	}
+	return p
+}
+
+// numberGlyphs converts a string with digits, dots, dashes and spaces to
+// glyphs. A dot is added to the preceding glyph.
+func numberGlyphs(s string) []Glyph {
+	g := make([]Glyph, 0, len(s))
+	for _, c := range s {
+		switch {
+		case c >= '0' && c <= '9':
+			g = append(g, DigitGlyph(int(c-'0')))
+		case c == '-':
+			g = append(g, GlyphDash)
+		case c == '.':
+			if len(g) == 0 || g[len(g)-1].Dot() {
+				g = append(g, GlyphBlank)
+			}
+			g[len(g)-1] = g[len(g)-1].WithDot()
+		default:
+			g = append(g, GlyphBlank)
+		}
+	}
+	return g
+}
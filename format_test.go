@@ -0,0 +1,111 @@
+package fpanels
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+// glyphsString returns the glyphs as a string, with a | around each glyph
+// so that blanks and dots can be seen
+func glyphsString(g []Glyph) string {
+	var b strings.Builder
+	b.WriteString("|")
+	for _, c := range g {
+		b.WriteString(c.String())
+		b.WriteString("|")
+	}
+	return b.String()
+}
+
+func TestFormatInt(t *testing.T) {
+	tests := []struct {
+		n     int
+		width int
+		f     Format
+		want  string
+		err   error
+	}{
+		{123, 5, Format{}, "| | |1|2|3|", nil},
+		{123, 5, Format{Align: AlignLeft}, "|1|2|3| | |", nil},
+		{123, 5, Format{Align: AlignCenter}, "| |1|2|3| |", nil},
+		{123, 5, Format{ZeroPad: true}, "|0|0|1|2|3|", nil},
+		{-12, 5, Format{ZeroPad: true}, "|-|0|0|1|2|", nil},
+		{-12, 5, Format{}, "| | |-|1|2|", nil},
+		{0, 1, Format{}, "|0|", nil},
+		{12345, 5, Format{}, "|1|2|3|4|5|", nil},
+		{123456, 5, Format{}, "|1|2|3|4|5|", nil},
+		{123456, 5, Format{Overflow: OverflowSaturate}, "|9|9|9|9|9|", nil},
+		{-123456, 5, Format{Overflow: OverflowSaturate}, "|-|9|9|9|9|", nil},
+		{123456, 5, Format{Overflow: OverflowDashes}, "|-|-|-|-|-|", nil},
+		{123456, 5, Format{Overflow: OverflowError}, "|", ErrOverflow},
+		{12, 0, Format{}, "|", nil},
+		{12, 0, Format{Overflow: OverflowSaturate}, "|", nil},
+		{-12, 1, Format{Overflow: OverflowSaturate}, "|-|", nil},
+		{-12, 2, Format{Overflow: OverflowSaturate}, "|-|9|", nil},
+		{12, -1, Format{}, "|", ErrInvalidWidth},
+	}
+	for _, tt := range tests {
+		g, err := FormatInt(tt.n, tt.width, tt.f)
+		if s := glyphsString(g); s != tt.want || err != tt.err {
+			t.Errorf("FormatInt(%d, %d, %+v) = %s, %v, want %s, %v", tt.n, tt.width, tt.f, s, err, tt.want, tt.err)
+		}
+	}
+}
+
+func TestFormatFloat(t *testing.T) {
+	tests := []struct {
+		x     float64
+		width int
+		f     Format
+		want  string
+		err   error
+	}{
+		{1.5, 5, Format{Decimals: 1}, "| | | |1.|5|", nil},
+		{1.25, 5, Format{Decimals: 2}, "| | |1.|2|5|", nil},
+		{1.5, 5, Format{Decimals: 1, ZeroPad: true}, "|0|0|0|1.|5|", nil},
+		{-1.5, 5, Format{Decimals: 1, ZeroPad: true}, "|-|0|0|1.|5|", nil},
+		{1.5, 5, Format{Decimals: 1, Align: AlignLeft}, "|1.|5| | | |", nil},
+		{-0.04, 5, Format{Decimals: 1}, "| | | |0.|0|", nil},
+		{118.25, 5, Format{Decimals: 3}, "|1|1|8.|2|5|", nil},
+		{12345.678, 5, Format{Decimals: 2}, "|1|2|3|4|6|", nil},
+		{123456.7, 5, Format{Decimals: 1}, "|1|2|3|4|5|", nil},
+		{123456.7, 5, Format{Decimals: 1, Overflow: OverflowSaturate}, "|9|9|9|9.|9|", nil},
+		{-123456.7, 5, Format{Decimals: 1, Overflow: OverflowSaturate}, "|-|9|9|9.|9|", nil},
+		{123456.7, 5, Format{Overflow: OverflowDashes}, "|-|-|-|-|-|", nil},
+		{123456.7, 5, Format{Overflow: OverflowError}, "|", ErrOverflow},
+		{math.Inf(1), 3, Format{}, "|9|9|9|", nil},
+		{math.Inf(-1), 3, Format{}, "|-|9|9|", nil},
+		{math.NaN(), 3, Format{Overflow: OverflowDashes}, "|-|-|-|", nil},
+		{math.NaN(), 3, Format{}, "|", ErrOverflow},
+		{1.5, 0, Format{Decimals: 1, Overflow: OverflowSaturate}, "|", nil},
+		{-1.5, 1, Format{Decimals: 1, Overflow: OverflowSaturate}, "|-|", nil},
+		{math.Inf(-1), 1, Format{}, "|-|", nil},
+		{1.5, -1, Format{}, "|", ErrInvalidWidth},
+	}
+	for _, tt := range tests {
+		g, err := FormatFloat(tt.x, tt.width, tt.f)
+		if s := glyphsString(g); s != tt.want || err != tt.err {
+			t.Errorf("FormatFloat(%v, %d, %+v) = %s, %v, want %s, %v", tt.x, tt.width, tt.f, s, err, tt.want, tt.err)
+		}
+	}
+}
+
+func TestNumberGlyphs(t *testing.T) {
+	tests := []struct {
+		s    string
+		want string
+	}{
+		{"", "|"},
+		{"12", "|1|2|"},
+		{"1.2", "|1.|2|"},
+		{".5", "| .|5|"},
+		{"1..2", "|1.| .|2|"},
+		{"-1 2", "|-|1| |2|"},
+	}
+	for _, tt := range tests {
+		if s := glyphsString(numberGlyphs(tt.s)); s != tt.want {
+			t.Errorf("numberGlyphs(%q) = %s, want %s", tt.s, s, tt.want)
+		}
+	}
+}
@@ -33,10 +33,11 @@ func main() {
 		multiPanel.DisplayInt(fpanels.Row2, i)
 
 	}
-	for i := byte(1); i != 0; i = i << 1 {
-		multiPanel.LEDsOn(i)
-		time.Sleep(100 * time.Millisecond)
-	}
+	fill := fpanels.Fill(0xff, 100*time.Millisecond)
+	fill.Repeat = 1
+	multiPanel.Animate(fill)
+	time.Sleep(9 * 100 * time.Millisecond)
+	multiPanel.LEDs(0xff)
 	switchPanel.LEDs(fpanels.LEDNRed | fpanels.LEDLRed | fpanels.LEDRGreen)
 	radioPanel.DisplayOff()
 	time.Sleep(500 * time.Millisecond)
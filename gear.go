@@ -0,0 +1,84 @@
+package fpanels
+
+import (
+	"time"
+)
+
+// Landing gear legs, used as indexes of GearIndicator.Extension
+const (
+	GearNose = iota
+	GearLeft
+	GearRight
+)
+
+// GearWarningBlink is the blink pattern of the gear unsafe warning
+var GearWarningBlink = Blink{Period: 500 * time.Millisecond, DutyCycle: 0.5}
+
+// gearGreen and gearRed are the green and red LEDs of each leg
+var (
+	gearGreen = [3]byte{LEDNGreen, LEDLGreen, LEDRGreen}
+	gearRed   = [3]byte{LEDNRed, LEDLRed, LEDRRed}
+)
+
+// GearIndicator is the state of the landing gear shown on the switch panel
+// LEDs. Each leg is shown green when it is down and locked, red when it is
+// in transit and off when it is up.
+//
+// The gear unsafe warning blinks the red LEDs of the legs that are not down
+// and locked. It is given when the throttle is at idle, or when Warn is
+// set, while the gear is not down and locked, see Warning.
+type GearIndicator struct {
+	// Extension is the extension of the nose, left and right legs from 0,
+	// up, to 1, down and locked. See GearNose, GearLeft and GearRight.
+	Extension [3]float64
+	// ThrottleIdle is true if the throttle is at idle
+	ThrottleIdle bool
+	// Warn is true for other conditions that require the gear down, for
+	// example flaps extended for landing
+	Warn bool
+}
+
+// Down returns true if all legs are down and locked
+func (g GearIndicator) Down() bool {
+	for _, e := range g.Extension {
+		if e < 1 {
+			return false
+		}
+	}
+	return true
+}
+
+// Warning returns true if the gear unsafe warning is given
+func (g GearIndicator) Warning() bool {
+	return (g.ThrottleIdle || g.Warn) && !g.Down()
+}
+
+// LEDs returns the gear LEDs that are on, and the LEDs that blink. See the
+// LED* constants.
+func (g GearIndicator) LEDs() (leds byte, blink byte) {
+	warning := g.Warning()
+	for leg, e := range g.Extension {
+		switch {
+		case e >= 1:
+			leds |= gearGreen[leg]
+		case warning:
+			leds |= gearRed[leg]
+			blink |= gearRed[leg]
+		case e > 0:
+			leds |= gearRed[leg]
+		}
+	}
+	return leds, blink
+}
+
+// ShowGear shows the gear indicator g on the landing gear LEDs
+func (panel *SwitchPanel) ShowGear(g GearIndicator) {
+	leds, blink := g.LEDs()
+	panel.displayMutex.Lock()
+	defer panel.displayMutex.Unlock()
+	panel.writeLEDs(leds, LEDAll)
+	panel.writeBlink(0, LEDAll&^blink, NoBlink)
+	if blink != 0 {
+		panel.writeBlink(0, blink, GearWarningBlink)
+	}
+}
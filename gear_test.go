@@ -0,0 +1,87 @@
+package fpanels
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGearIndicatorLEDs(t *testing.T) {
+	tests := []struct {
+		extension [3]float64
+		idle      bool
+		warn      bool
+		leds      byte
+		blink     byte
+		down      bool
+	}{
+		{[3]float64{0, 0, 0}, false, false, 0, 0, false},
+		{[3]float64{0.5, 0.5, 0.5}, false, false, LEDAllRed, 0, false},
+		{[3]float64{1, 1, 1}, false, false, LEDAllGreen, 0, true},
+		{[3]float64{1, 0.5, 0}, false, false, LEDNGreen | LEDLRed, 0, false},
+		{[3]float64{0, 0, 0}, true, false, LEDAllRed, LEDAllRed, false},
+		{[3]float64{1, 0.5, 0}, true, false, LEDNGreen | LEDLRed | LEDRRed, LEDLRed | LEDRRed, false},
+		{[3]float64{1, 1, 1}, true, false, LEDAllGreen, 0, true},
+		{[3]float64{0.5, 0, 1}, false, true, LEDNRed | LEDLRed | LEDRGreen, LEDNRed | LEDLRed, false},
+		{[3]float64{1, 1, 1}, false, true, LEDAllGreen, 0, true},
+	}
+	for _, tt := range tests {
+		g := GearIndicator{Extension: tt.extension, ThrottleIdle: tt.idle, Warn: tt.warn}
+		leds, blink := g.LEDs()
+		if leds != tt.leds || blink != tt.blink || g.Down() != tt.down {
+			t.Errorf("%+v: LEDs %06b, %06b, down %v, want %06b, %06b, %v", g, leds, blink, g.Down(), tt.leds, tt.blink, tt.down)
+		}
+	}
+}
+
+func TestGearLegLEDs(t *testing.T) {
+	tests := []struct {
+		extension float64
+		green     bool
+		red       bool
+	}{
+		{0, false, false},
+		{0.5, false, true},
+		{1, true, false},
+	}
+	for leg := GearNose; leg <= GearRight; leg++ {
+		for _, tt := range tests {
+			var g GearIndicator
+			g.Extension = [3]float64{1, 1, 1}
+			g.Extension[leg] = tt.extension
+			leds, _ := g.LEDs()
+			want := LEDAllGreen &^ gearGreen[leg]
+			if tt.green {
+				want |= gearGreen[leg]
+			}
+			if tt.red {
+				want |= gearRed[leg]
+			}
+			if leds != want {
+				t.Errorf("Leg %d at %v: LEDs %06b, want %06b", leg, tt.extension, leds, want)
+			}
+		}
+	}
+}
+
+func TestShowGear(t *testing.T) {
+	ms := time.Millisecond
+	panel := newTestSwitchPanel()
+	panel.ShowGear(GearIndicator{Extension: [3]float64{1, 0.5, 0}, ThrottleIdle: true})
+	if f, _ := panel.render(100 * ms); f[0] != LEDNGreen|LEDLRed|LEDRRed {
+		t.Errorf("Warning on phase = %06b", f[0])
+	}
+	if f, _ := panel.render(300 * ms); f[0] != LEDNGreen {
+		t.Errorf("Warning off phase = %06b, want %06b", f[0], LEDNGreen)
+	}
+	panel.ShowGear(GearIndicator{Extension: [3]float64{0, 0, 0}, Warn: true})
+	if f, _ := panel.render(100 * ms); f[0] != LEDAllRed {
+		t.Errorf("Warn on phase = %06b, want %06b", f[0], LEDAllRed)
+	}
+	if f, _ := panel.render(300 * ms); f[0] != 0 {
+		t.Errorf("Warn off phase = %06b, want 0", f[0])
+	}
+	panel.ShowGear(GearIndicator{Extension: [3]float64{1, 1, 1}, ThrottleIdle: true})
+	if f, next := panel.render(300 * ms); f[0] != LEDAllGreen || next != 0 {
+		t.Errorf("Down and locked = %06b, %v, want %06b without blinking", f[0], next, LEDAllGreen)
+	}
+}
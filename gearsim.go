@@ -0,0 +1,169 @@
+package fpanels
+
+import (
+	"sync"
+	"time"
+)
+
+// gearSimInterval is the time between gear simulation updates
+const gearSimInterval = 50 * time.Millisecond
+
+// GearSimulator makes the landing gear LEDs of the switch panel follow the
+// gear lever without a simulator. Moving the lever moves the gear legs, and
+// the LEDs show the legs red while in transit and then green or off, see
+// GearIndicator. The legs move at slightly different speeds.
+//
+// The gear only moves, and the LEDs are only lit, while the SwBat master
+// switch is on. A lever moved without power moves the gear when the power
+// is turned on.
+type GearSimulator struct {
+	panel   *SwitchPanel
+	mutex   sync.Mutex
+	gear    GearIndicator
+	target  float64
+	powered bool
+	transit [3]time.Duration
+	last    time.Time
+	timer   *time.Timer
+	// gen identifies the current timer, so that a tick of a stopped timer
+	// that has already fired is ignored
+	gen int
+}
+
+// NewGearSimulator creates a new gear simulator for the switch panel and
+// starts handling its switch events. The gear starts in the position of the
+// lever, or down if the position is not yet known. Each leg takes about
+// five seconds to move.
+func NewGearSimulator(panel *SwitchPanel) *GearSimulator {
+	g := &GearSimulator{
+		panel:   panel,
+		target:  1,
+		powered: panel.IsSwitchSet(SwBat),
+	}
+	if panel.IsSwitchSet(GearUp) {
+		g.target = 0
+	}
+	for leg := range g.gear.Extension {
+		g.gear.Extension[leg] = g.target
+	}
+	g.SetTransit(5*time.Second, 0.1)
+	panel.AddSwitchHandler(g)
+	return g
+}
+
+// SetTransit sets the time d it takes a leg to move between up and down.
+// The left leg is slower and the right leg faster by the fraction
+// asymmetry, for example 0.1 makes them take 10% longer and shorter.
+func (g *GearSimulator) SetTransit(d time.Duration, asymmetry float64) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	g.transit[GearNose] = d
+	g.transit[GearLeft] = time.Duration(float64(d) * (1 + asymmetry))
+	g.transit[GearRight] = time.Duration(float64(d) * (1 - asymmetry))
+	g.show()
+}
+
+// Gear returns the simulated gear
+func (g *GearSimulator) Gear() GearIndicator {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	return g.gear
+}
+
+// HandleSwitch handles the switch panel switch event s. It is called by the
+// panel, see AddSwitchHandler.
+func (g *GearSimulator) HandleSwitch(s SwitchState) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	switch {
+	case s.Switch == SwBat:
+		g.powered = s.On
+	case s.Switch == GearUp && s.On:
+		g.target = 0
+	case s.Switch == GearDown && s.On:
+		g.target = 1
+	default:
+		return
+	}
+	g.show()
+}
+
+// show shows the gear on the LEDs and starts moving the gear if it is not
+// in the lever position. The simulator mutex must be held.
+func (g *GearSimulator) show() {
+	if !g.powered {
+		g.panel.ShowGear(GearIndicator{})
+		g.stop()
+		return
+	}
+	g.panel.ShowGear(g.gear)
+	if g.timer == nil && !g.settled() {
+		g.last = time.Now()
+		g.gen++
+		gen := g.gen
+		g.timer = time.AfterFunc(gearSimInterval, func() { g.tick(gen) })
+	}
+}
+
+// tick moves the gear legs towards the lever position. gen is the
+// generation of the timer that fired.
+func (g *GearSimulator) tick(gen int) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	if gen != g.gen || g.timer == nil {
+		return
+	}
+	g.timer = nil
+	now := time.Now()
+	elapsed := now.Sub(g.last)
+	g.last = now
+	for leg, e := range g.gear.Extension {
+		step := 1.0
+		if g.transit[leg] > 0 {
+			step = float64(elapsed) / float64(g.transit[leg])
+		}
+		switch {
+		case e < g.target:
+			g.gear.Extension[leg] = minFloat(e+step, g.target)
+		case e > g.target:
+			g.gear.Extension[leg] = maxFloat(e-step, g.target)
+		}
+	}
+	g.show()
+}
+
+// stop stops moving the gear. The simulator mutex must be held.
+func (g *GearSimulator) stop() {
+	g.gen++
+	if g.timer != nil {
+		g.timer.Stop()
+		g.timer = nil
+	}
+}
+
+// settled returns true if all legs are in the lever position. The simulator
+// mutex must be held.
+func (g *GearSimulator) settled() bool {
+	for _, e := range g.gear.Extension {
+		if e != g.target {
+			return false
+		}
+	}
+	return true
+}
+
+// minFloat returns the smaller of a and b
+func minFloat(a, b float64) float64 {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// maxFloat returns the larger of a and b
+func maxFloat(a, b float64) float64 {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,78 @@
+package fpanels
+
+import (
+	"testing"
+	"time"
+)
+
+// waitLEDs waits up to a second for the LEDs of the switch panel to become
+// leds. It returns true if any frame on the way showed the LEDs via.
+func waitLEDs(t *testing.T, panel *SwitchPanel, leds byte, via byte) bool {
+	t.Helper()
+	seen := false
+	for start := time.Now(); time.Since(start) < time.Second; time.Sleep(5 * time.Millisecond) {
+		f, _ := panel.render(0)
+		seen = seen || f[0] == via
+		if f[0] == leds {
+			return seen
+		}
+	}
+	f, _ := panel.render(0)
+	t.Fatalf("LEDs = %06b, want %06b", f[0], leds)
+	return false
+}
+
+func TestGearSimulator(t *testing.T) {
+	panel := newTestSwitchPanel()
+	panel.switches = 1<<SwBat | 1<<GearDown
+	g := NewGearSimulator(panel)
+	g.SetTransit(200*time.Millisecond, 0.1)
+	if f, _ := panel.render(0); f[0] != LEDAllGreen || !g.Gear().Down() {
+		t.Fatalf("Initial LEDs = %06b, want %06b", f[0], LEDAllGreen)
+	}
+
+	panel.setSwitch(GearDown, false)
+	panel.setSwitch(GearUp, true)
+	if !waitLEDs(t, panel, 0, LEDAllRed) {
+		t.Error("Gear not shown in transit while retracting")
+	}
+	if g.Gear().Extension != [3]float64{0, 0, 0} {
+		t.Errorf("Retracted gear = %v", g.Gear().Extension)
+	}
+
+	panel.setSwitch(SwBat, false)
+	panel.setSwitch(GearUp, false)
+	panel.setSwitch(GearDown, true)
+	time.Sleep(100 * time.Millisecond)
+	if f, _ := panel.render(0); f[0] != 0 || g.Gear().Extension != [3]float64{0, 0, 0} {
+		t.Errorf("Gear moved without power to %v, LEDs %06b", g.Gear().Extension, f[0])
+	}
+	panel.setSwitch(SwBat, true)
+	if !waitLEDs(t, panel, LEDAllGreen, LEDAllRed) {
+		t.Error("Gear not shown in transit while extending")
+	}
+}
+
+func TestGearSimulatorStaleTick(t *testing.T) {
+	panel := newTestSwitchPanel()
+	panel.switches = 1<<SwBat | 1<<GearDown
+	g := NewGearSimulator(panel)
+	panel.setSwitch(GearDown, false)
+	panel.setSwitch(GearUp, true)
+	g.mutex.Lock()
+	stale := g.gen
+	g.mutex.Unlock()
+	panel.setSwitch(SwBat, false)
+	panel.setSwitch(SwBat, true)
+	defer panel.setSwitch(SwBat, false)
+	g.mutex.Lock()
+	timer, last, extension := g.timer, g.last, g.gear.Extension
+	g.mutex.Unlock()
+	// The tick of the first timer runs after the power came back
+	g.tick(stale)
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	if g.timer != timer || g.last != last || g.gear.Extension != extension {
+		t.Error("Stale tick moved the gear")
+	}
+}
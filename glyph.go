@@ -0,0 +1,129 @@
+package fpanels
+
+// Glyph is a single character position on a segment display. A glyph is
+// either a digit 0-9, a blank or a dash. A digit or blank can additionally
+// have a dot. Use DigitGlyph to create digit glyphs.
+//
+// Glyphs hide the panel specific encoding of the segment displays. Not all
+// panels can show all glyphs, see the SetDigit functions of the panels.
+type Glyph uint8
+
+// Glyphs that are not digits
+const (
+	GlyphBlank Glyph = 0x0f
+	GlyphDash  Glyph = 0x0e
+)
+
+const glyphDot Glyph = 0x10
+
+// DigitGlyph returns the glyph for the digit n. If n is outside the
+// range 0-9 then GlyphBlank is returned.
+func DigitGlyph(n int) Glyph {
+	if n < 0 || n > 9 {
+		return GlyphBlank
+	}
+	return Glyph(n)
+}
+
+// WithDot returns the glyph g with a dot added. Dashes can not have a dot,
+// so for GlyphDash g is returned as is.
+func (g Glyph) WithDot() Glyph {
+	if g.base() == GlyphDash {
+		return g
+	}
+	return g | glyphDot
+}
+
+// Dot returns true if the glyph has a dot
+func (g Glyph) Dot() bool {
+	return g&glyphDot != 0
+}
+
+// Digit returns the digit shown by the glyph. ok is false if the glyph is
+// not a digit.
+func (g Glyph) Digit() (n int, ok bool) {
+	b := g.base()
+	if b > 9 {
+		return 0, false
+	}
+	return int(b), true
+}
+
+// String returns the glyph as a string in the format used by the
+// DisplayString functions, for example "8", "8.", "-" or " "
+func (g Glyph) String() string {
+	var s string
+	switch b := g.base(); {
+	case b <= 9:
+		s = string(rune('0' + b))
+	case b == GlyphDash:
+		s = "-"
+	default:
+		s = " "
+	}
+	if g.Dot() {
+		s += "."
+	}
+	return s
+}
+
+// base returns the glyph without the dot
+func (g Glyph) base() Glyph {
+	b := g &^ glyphDot
+	if b > 9 && b != GlyphDash {
+		return GlyphBlank
+	}
+	return b
+}
+
+// radioGlyphByte encodes g for the radio panel segment displays
+func radioGlyphByte(g Glyph) byte {
+	b := g.base()
+	switch {
+	case b == GlyphDash:
+		return dash
+	case g.Dot():
+		return byte(b) | dot
+	}
+	return byte(b)
+}
+
+// radioByteGlyph decodes a radio panel segment display byte
+func radioByteGlyph(b byte) Glyph {
+	switch b & 0xf0 {
+	case dash & 0xf0:
+		return GlyphDash
+	case dot:
+		return Glyph(b & 0x0f).base().WithDot()
+	}
+	return Glyph(b & 0x0f).base()
+}
+
+// multiGlyphByte encodes g for the multi panel segment display. ok is
+// false if the display can not show the glyph.
+func multiGlyphByte(display DisplayID, g Glyph) (b byte, ok bool) {
+	if g.Dot() {
+		return 0, false
+	}
+	switch g = g.base(); {
+	case g == GlyphDash:
+		if display != Row2 {
+			return 0, false
+		}
+		return multiDash, true
+	case g == GlyphBlank:
+		return blank, true
+	}
+	return byte(g), true
+}
+
+// multiByteGlyph decodes a multi panel segment display byte
+func multiByteGlyph(b byte) Glyph {
+	if b == multiDash {
+		return GlyphDash
+	}
+	if b > 9 {
+		return GlyphBlank
+	}
+	return Glyph(b)
+}
@@ -0,0 +1,85 @@
+package fpanels
+
+import "testing"
+
+func TestGlyph(t *testing.T) {
+	tests := []struct {
+		g     Glyph
+		s     string
+		digit int
+		ok    bool
+		dot   bool
+	}{
+		{DigitGlyph(0), "0", 0, true, false},
+		{DigitGlyph(9), "9", 9, true, false},
+		{DigitGlyph(10), " ", 0, false, false},
+		{DigitGlyph(-1), " ", 0, false, false},
+		{DigitGlyph(7).WithDot(), "7.", 7, true, true},
+		{GlyphBlank, " ", 0, false, false},
+		{GlyphBlank.WithDot(), " .", 0, false, true},
+		{GlyphDash, "-", 0, false, false},
+		{GlyphDash.WithDot(), "-", 0, false, false},
+	}
+	for _, tt := range tests {
+		if s := tt.g.String(); s != tt.s {
+			t.Errorf("Glyph(%#x).String() = %q, want %q", uint8(tt.g), s, tt.s)
+		}
+		if digit, ok := tt.g.Digit(); digit != tt.digit || ok != tt.ok {
+			t.Errorf("Glyph(%#x).Digit() = %d, %v, want %d, %v", uint8(tt.g), digit, ok, tt.digit, tt.ok)
+		}
+		if dot := tt.g.Dot(); dot != tt.dot {
+			t.Errorf("Glyph(%#x).Dot() = %v, want %v", uint8(tt.g), dot, tt.dot)
+		}
+	}
+}
+
+func TestRadioGlyphByte(t *testing.T) {
+	tests := []struct {
+		g Glyph
+		b byte
+	}{
+		{DigitGlyph(0), 0x00},
+		{DigitGlyph(5), 0x05},
+		{DigitGlyph(5).WithDot(), 0xd5},
+		{GlyphBlank, blank},
+		{GlyphBlank.WithDot(), 0xdf},
+		{GlyphDash, dash},
+	}
+	for _, tt := range tests {
+		b := radioGlyphByte(tt.g)
+		if b != tt.b {
+			t.Errorf("radioGlyphByte(%q) = %#x, want %#x", tt.g, b, tt.b)
+		}
+		if g := radioByteGlyph(b); g != tt.g {
+			t.Errorf("radioByteGlyph(%#x) = %q, want %q", b, g, tt.g)
+		}
+	}
+}
+
+func TestMultiGlyphByte(t *testing.T) {
+	tests := []struct {
+		display DisplayID
+		g       Glyph
+		b       byte
+		ok      bool
+	}{
+		{Row1, DigitGlyph(0), 0x00, true},
+		{Row2, DigitGlyph(9), 0x09, true},
+		{Row1, GlyphBlank, blank, true},
+		{Row1, GlyphDash, 0, false},
+		{Row2, GlyphDash, multiDash, true},
+		{Row1, DigitGlyph(3).WithDot(), 0, false},
+	}
+	for _, tt := range tests {
+		b, ok := multiGlyphByte(tt.display, tt.g)
+		if b != tt.b || ok != tt.ok {
+			t.Errorf("multiGlyphByte(%d, %q) = %#x, %v, want %#x, %v", tt.display, tt.g, b, ok, tt.b, tt.ok)
+		}
+		if !ok {
+			continue
+		}
+		if g := multiByteGlyph(b); g != tt.g {
+			t.Errorf("multiByteGlyph(%#x) = %q, want %q", b, g, tt.g)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package fpanels
+
+import (
+	"time"
+)
+
+// IdleMode is what an idle panel shows
+type IdleMode int
+
+// Idle modes
+const (
+	// IdleBlank turns off all displays and LEDs
+	IdleBlank IdleMode = iota
+	// IdleDim dims all displays and LEDs to IdlePolicy.Level
+	IdleDim
+)
+
+// IdlePolicy decides when a panel is idle and what it then shows
+type IdlePolicy struct {
+	// Timeout is the time without switch events and display changes
+	// after which the panel is idle. Display updates that show the same
+	// values are not counted. Zero turns off idle handling.
+	Timeout time.Duration
+	Mode    IdleMode
+	// Level is the brightness, between 0 and 1, of the displays and LEDs
+	// in the IdleDim mode. The displays are dimmed by showing and blanking
+	// them at the highest rate the USB link can sustain.
+	Level float64
+}
+
+// IdleState is sent on the idle channel when a panel becomes idle and when
+// it is woken up again
+type IdleState struct {
+	Panel PanelID
+	Idle  bool
+}
+
+// SetIdlePolicy sets the idle policy of the panel. When the panel has been
+// idle for p.Timeout the displays and LEDs are blanked or dimmed. The
+// previous displays and LEDs are restored on the next switch event or
+// display change. Both transitions are reported on the channel returned by
+// IdleCh.
+func (panel *panel) SetIdlePolicy(p IdlePolicy) {
+	panel.displayMutex.Lock()
+	defer panel.displayMutex.Unlock()
+	panel.idlePolicy = p
+	panel.touch()
+	panel.setDirty()
+}
+
+// IdleCh returns a channel for idle state changes
+func (panel *panel) IdleCh() chan IdleState {
+	return panel.idleCh
+}
+
+// changed records a display update and tells the display refresher that
+// the display must be updated. The display mutex must be held.
+func (panel *panel) changed() {
+	panel.touch()
+	panel.setDirty()
+}
+
+// touch records activity on the panel, and wakes it up if it is idle. The
+// display mutex must be held.
+func (panel *panel) touch() {
+	panel.lastActivity = time.Now()
+	if panel.idle {
+		panel.setIdle(false)
+		panel.setDirty()
+	}
+}
+
+// setIdle sets the idle state and reports it on the idle channel. The
+// display mutex must be held.
+func (panel *panel) setIdle(idle bool) {
+	panel.idle = idle
+	select {
+	case panel.idleCh <- IdleState{panel.id, idle}:
+	default:
+	}
+}
+
+// checkIdle makes the panel idle if it has been inactive for the idle
+// timeout at the time now. It returns the time until the panel becomes
+// idle, or 0 if it is already idle or idle handling is off. The display
+// mutex must be held.
+func (panel *panel) checkIdle(now time.Time) time.Duration {
+	if panel.idlePolicy.Timeout <= 0 || panel.idle {
+		return 0
+	}
+	left := panel.lastActivity.Add(panel.idlePolicy.Timeout).Sub(now)
+	if left > 0 {
+		return left
+	}
+	panel.setIdle(true)
+	return 0
+}
+
+// idleBlank returns true if the panel is idle and should be blanked. The
+// display mutex must be held.
+func (panel *panel) idleBlank() bool {
+	return panel.idle && (panel.idlePolicy.Mode == IdleBlank || panel.idlePolicy.Level <= 0)
+}
+
+// applyIdleDim blanks buf in the frames that should be dark when the panel
+// is idle and dimmed. The display mutex must be held.
+func (panel *panel) applyIdleDim(buf []byte) {
+	if !panel.idle || panel.idlePolicy.Mode != IdleDim || panel.idlePolicy.Level >= 1 {
+		return
+	}
+	panel.continuous = true
+	panel.idleAcc += panel.idlePolicy.Level
+	// Allow for rounding errors in the accumulated level
+	if panel.idleAcc >= 1-1e-9 {
+		panel.idleAcc--
+		return
+	}
+	copy(buf, panel.blankFrame)
+}
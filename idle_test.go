@@ -0,0 +1,57 @@
+package fpanels
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestIdleBlank(t *testing.T) {
+	panel := newTestMultiPanel()
+	panel.DisplayString(Row1, "12345")
+	panel.SetIdlePolicy(IdlePolicy{Timeout: time.Second, Mode: IdleBlank})
+	f, next := panel.render(0)
+	if !bytes.Equal(f, panel.displayState) || next < time.Second || next > 2*time.Second {
+		t.Errorf("Active frame = %x, %v, want %x, about %v", f, next, panel.displayState, time.Second)
+	}
+	if f, _ = panel.render(2 * time.Second); !bytes.Equal(f, panel.blankFrame) {
+		t.Errorf("Idle frame = %x, want %x", f, panel.blankFrame)
+	}
+	if s := <-panel.idleCh; s != (IdleState{Multi, true}) {
+		t.Errorf("Idle state = %+v, want idle", s)
+	}
+	panel.DisplayString(Row2, "1")
+	if s := <-panel.idleCh; s != (IdleState{Multi, false}) {
+		t.Errorf("Idle state after update = %+v, want active", s)
+	}
+	if f, _ = panel.render(0); !bytes.Equal(f, panel.displayState) {
+		t.Errorf("Woken frame = %x, want %x", f, panel.displayState)
+	}
+}
+
+func TestIdleDim(t *testing.T) {
+	panel := newTestMultiPanel()
+	panel.DisplayString(Row1, "12345")
+	panel.SetIdlePolicy(IdlePolicy{Timeout: time.Second, Mode: IdleDim, Level: 0.5})
+	shown := 0
+	for i := 0; i < 10; i++ {
+		f, _ := panel.render(2 * time.Second)
+		switch {
+		case bytes.Equal(f, panel.displayState):
+			shown++
+		case !bytes.Equal(f, panel.blankFrame):
+			t.Fatalf("Dimmed frame = %x", f)
+		}
+	}
+	if shown != 5 || !panel.continuous {
+		t.Errorf("Shown in %d of 10 frames (continuous %v), want 5", shown, panel.continuous)
+	}
+}
+
+func TestIdleOff(t *testing.T) {
+	panel := newTestMultiPanel()
+	panel.SetIdlePolicy(IdlePolicy{})
+	if _, next := panel.render(time.Hour); panel.idle || next != 0 {
+		t.Errorf("Idle %v, next %v with idle handling off", panel.idle, next)
+	}
+}
@@ -0,0 +1,93 @@
+package fpanels
+
+import (
+	"time"
+)
+
+// layer is a named display layer. The bits set in mask cover the display
+// state with the bits in data.
+type layer struct {
+	name     string
+	priority int
+	expires  time.Time
+	data     []byte
+	mask     []byte
+}
+
+// setLayer sets the bytes data covered by mask, starting at display state
+// index start, in the layer with the given name. The layer is created if it
+// does not exist.
+func (panel *panel) setLayer(name string, priority int, timeout time.Duration, start int, data []byte, mask []byte) {
+	panel.displayMutex.Lock()
+	defer panel.displayMutex.Unlock()
+	var l *layer
+	for i, ll := range panel.layers {
+		if ll.name == name {
+			l = ll
+			panel.layers = append(panel.layers[:i], panel.layers[i+1:]...)
+			break
+		}
+	}
+	if l == nil {
+		l = &layer{
+			name: name,
+			data: make([]byte, len(panel.displayState)),
+			mask: make([]byte, len(panel.displayState)),
+		}
+	}
+	l.priority = priority
+	l.expires = time.Time{}
+	if timeout > 0 {
+		l.expires = time.Now().Add(timeout)
+	}
+	for i := range data {
+		l.data[start+i] = l.data[start+i]&^mask[i] | data[i]&mask[i]
+		l.mask[start+i] |= mask[i]
+	}
+	// Keep the layers sorted by priority
+	i := len(panel.layers)
+	for i > 0 && panel.layers[i-1].priority > priority {
+		i--
+	}
+	panel.layers = append(panel.layers, nil)
+	copy(panel.layers[i+1:], panel.layers[i:])
+	panel.layers[i] = l
+	panel.changed()
+}
+
+// RemoveLayer removes the display layer with the given name. The displays
+// and LEDs show the layers below it, or the display state set by the
+// Display* and LED* functions.
+func (panel *panel) RemoveLayer(name string) {
+	panel.displayMutex.Lock()
+	defer panel.displayMutex.Unlock()
+	for i, l := range panel.layers {
+		if l.name == name {
+			panel.layers = append(panel.layers[:i], panel.layers[i+1:]...)
+			panel.changed()
+			return
+		}
+	}
+}
+
+// applyLayers draws the layers over buf, lowest priority first, and removes
+// expired layers. It returns the time until the next layer expires, or 0 if
+// no layer has a timeout. The display mutex must be held.
+func (panel *panel) applyLayers(buf []byte, now time.Time) time.Duration {
+	var next time.Duration
+	layers := panel.layers[:0]
+	for _, l := range panel.layers {
+		if !l.expires.IsZero() {
+			if !now.Before(l.expires) {
+				continue
+			}
+			next = earliest(next, l.expires.Sub(now))
+		}
+		layers = append(layers, l)
+		for i := range buf {
+			buf[i] = buf[i]&^l.mask[i] | l.data[i]&l.mask[i]
+		}
+	}
+	panel.layers = layers
+	return next
+}
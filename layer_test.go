@@ -0,0 +1,51 @@
+package fpanels
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestLayers(t *testing.T) {
+	b := byte(blank)
+	panel := newTestMultiPanel()
+	panel.DisplayString(Row1, "11111")
+	panel.LEDs(LEDHDG | LEDNAV)
+	row1 := func(at time.Duration) []byte {
+		f, _ := panel.render(at)
+		return f[:5]
+	}
+	steps := []struct {
+		name string
+		do   func()
+		at   time.Duration
+		want []byte
+	}{
+		{"low", func() { panel.LayerGlyphs("low", 1, 0, Row1, numberGlyphs("22")) }, 0, []byte{b, b, b, 2, 2}},
+		{"high", func() { panel.LayerGlyphs("high", 2, time.Second, Row1, numberGlyphs("3")) }, 0, []byte{b, b, b, b, 3}},
+		{"expired", func() {}, 2 * time.Second, []byte{b, b, b, 2, 2}},
+		{"raised", func() {
+			panel.LayerGlyphs("high", 2, 0, Row1, numberGlyphs("3"))
+			panel.LayerGlyphs("low", 3, 0, Row1, numberGlyphs("22"))
+		}, 0, []byte{b, b, b, 2, 2}},
+		{"removed", func() { panel.RemoveLayer("low") }, 0, []byte{b, b, b, b, 3}},
+		{"removed all", func() { panel.RemoveLayer("high") }, 0, []byte{1, 1, 1, 1, 1}},
+	}
+	for _, s := range steps {
+		s.do()
+		if got := row1(s.at); !bytes.Equal(got, s.want) {
+			t.Errorf("%s: row 1 = %x, want %x", s.name, got, s.want)
+		}
+	}
+	if len(panel.layers) != 0 {
+		t.Errorf("%d layers left", len(panel.layers))
+	}
+
+	panel.LayerLEDs("leds", 0, 0, LEDAP, LEDAP|LEDHDG)
+	if f, _ := panel.render(0); f[10] != LEDAP|LEDNAV {
+		t.Errorf("LED layer = %#x, want %#x", f[10], LEDAP|LEDNAV)
+	}
+	if err := panel.LayerGlyphs("bad", 0, 0, Row1, numberGlyphs("123456")); err != ErrOverflow {
+		t.Errorf("LayerGlyphs overflow = %v, want %v", err, ErrOverflow)
+	}
+}
@@ -0,0 +1,88 @@
+package fpanels
+
+import (
+	"time"
+)
+
+// LEDState is the state of an autopilot mode shown on a multi panel button
+// LED
+type LEDState int
+
+// LED states
+const (
+	// LEDOff turns the LED off
+	LEDOff LEDState = iota
+	// LEDArmed shows an armed mode by blinking the LED, see SetArmedBlink
+	LEDArmed
+	// LEDEngaged shows an engaged mode with the LED steadily on
+	LEDEngaged
+)
+
+// DefaultArmedBlink is the blink pattern of armed modes
+var DefaultArmedBlink = Blink{Period: 600 * time.Millisecond, DutyCycle: 0.5}
+
+// SetLEDState sets the LEDs given by leds to the state s. All other LEDs
+// are left intact. See the LED* constants. Multiple LEDs can be ORed
+// together, for example
+//   panel.SetLEDState(LEDAPR, LEDArmed)
+//   panel.SetLEDState(LEDAP|LEDHDG, LEDEngaged)
+// All armed LEDs blink in phase with the same pattern. Any blink set by
+// BlinkLEDs on the LEDs is replaced.
+func (panel *MultiPanel) SetLEDState(leds byte, s LEDState) {
+	if leds == 0 {
+		return
+	}
+	panel.displayMutex.Lock()
+	defer panel.displayMutex.Unlock()
+	switch s {
+	case LEDArmed:
+		panel.armed |= leds
+		panel.writeLEDs(leds, leds)
+		panel.writeBlink(10, leds, panel.armedBlink)
+	case LEDEngaged:
+		panel.armed &^= leds
+		panel.writeLEDs(leds, leds)
+		panel.writeBlink(10, leds, NoBlink)
+	default:
+		panel.armed &^= leds
+		panel.writeLEDs(0, leds)
+		panel.writeBlink(10, leds, NoBlink)
+	}
+}
+
+// disarm clears the armed state of the LEDs given by mask and stops their
+// armed blink. The display mutex must be held.
+func (panel *MultiPanel) disarm(mask byte) {
+	armed := panel.armed & mask
+	if armed == 0 {
+		return
+	}
+	panel.armed &^= armed
+	panel.writeBlink(10, armed, NoBlink)
+}
+
+// LEDStateOf returns the state of the LED led, for example LEDAPR
+func (panel *MultiPanel) LEDStateOf(led byte) LEDState {
+	panel.displayMutex.Lock()
+	defer panel.displayMutex.Unlock()
+	switch {
+	case panel.armed&led != 0:
+		return LEDArmed
+	case panel.displayState[10]&led != 0:
+		return LEDEngaged
+	}
+	return LEDOff
+}
+
+// SetArmedBlink sets the blink pattern b of armed LEDs. For example, to
+// show armed modes dimmed instead of blinking, blink them faster than the
+// eye can follow:
+//   panel.SetArmedBlink(Blink{Period: 20 * time.Millisecond, DutyCycle: 0.3})
+func (panel *MultiPanel) SetArmedBlink(b Blink) {
+	panel.displayMutex.Lock()
+	defer panel.displayMutex.Unlock()
+	panel.armedBlink = b
+	if panel.armed != 0 {
+		panel.writeBlink(10, panel.armed, b)
+	}
+}
@@ -0,0 +1,68 @@
+package fpanels
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLEDState(t *testing.T) {
+	ms := time.Millisecond
+	panel := newTestMultiPanel()
+	panel.SetLEDState(LEDAPR, LEDArmed)
+	panel.SetLEDState(LEDAP|LEDHDG, LEDEngaged)
+	panel.SetLEDState(0, LEDOff)
+	steps := []struct {
+		name string
+		do   func()
+		at   time.Duration
+		leds byte
+	}{
+		{"armed on", func() {}, 100 * ms, LEDAPR | LEDAP | LEDHDG},
+		{"armed off", func() {}, 400 * ms, LEDAP | LEDHDG},
+		{"armed blink", func() { panel.SetArmedBlink(Blink{Period: time.Second, DutyCycle: 0.8}) }, 400 * ms, LEDAPR | LEDAP | LEDHDG},
+		{"engaged", func() { panel.SetLEDState(LEDAPR, LEDEngaged) }, 900 * ms, LEDAPR | LEDAP | LEDHDG},
+		{"off", func() { panel.SetLEDState(LEDAP, LEDOff) }, 900 * ms, LEDAPR | LEDHDG},
+		{"armed again", func() { panel.SetLEDState(LEDHDG, LEDArmed) }, 900 * ms, LEDAPR},
+	}
+	for _, s := range steps {
+		s.do()
+		if f, _ := panel.render(s.at); f[10] != s.leds {
+			t.Errorf("%s: LEDs at %v = %#x, want %#x", s.name, s.at, f[10], s.leds)
+		}
+	}
+	states := map[byte]LEDState{LEDAPR: LEDEngaged, LEDAP: LEDOff, LEDHDG: LEDArmed, LEDNAV: LEDOff}
+	for led, want := range states {
+		if s := panel.LEDStateOf(led); s != want {
+			t.Errorf("LEDStateOf(%#x) = %v, want %v", led, s, want)
+		}
+	}
+}
+
+func TestLEDsDisarm(t *testing.T) {
+	ms := time.Millisecond
+	tests := []struct {
+		name  string
+		set   func(panel *MultiPanel)
+		leds  byte
+		state LEDState
+	}{
+		{"LEDsOff", func(panel *MultiPanel) { panel.LEDsOff(LEDAPR) }, LEDAP, LEDOff},
+		{"LEDs", func(panel *MultiPanel) { panel.LEDs(LEDAPR) }, LEDAPR, LEDEngaged},
+		{"LEDsOn", func(panel *MultiPanel) { panel.LEDsOn(LEDAPR) }, LEDAPR | LEDAP, LEDEngaged},
+		{"LEDsOnOff off", func(panel *MultiPanel) { panel.LEDsOnOff(LEDAPR, 0) }, LEDAP, LEDOff},
+		{"LEDsOnOff on", func(panel *MultiPanel) { panel.LEDsOnOff(LEDAPR, 1) }, LEDAPR | LEDAP, LEDEngaged},
+	}
+	for _, tt := range tests {
+		panel := newTestMultiPanel()
+		panel.SetLEDState(LEDAPR|LEDAP, LEDArmed)
+		tt.set(panel)
+		if s := panel.LEDStateOf(LEDAPR); s != tt.state {
+			t.Errorf("%s: LEDStateOf(LEDAPR) = %v, want %v", tt.name, s, tt.state)
+		}
+		// The APR LED no longer blinks, while AP is still armed
+		want := tt.leds &^ LEDAP
+		if f, _ := panel.render(400 * ms); f[10] != want {
+			t.Errorf("%s: LEDs in blink off phase = %#x, want %#x", tt.name, f[10], want)
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package fpanels
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Magneto is the setting of the magneto/starter knob
+type Magneto int
+
+// Magneto settings, in the order of the knob positions
+const (
+	MagnetoOff Magneto = iota
+	MagnetoRight
+	MagnetoLeft
+	MagnetoBoth
+	MagnetoStart
+)
+
+// magnetoBounce is the time a knob position that is not next to the
+// previous position must be kept before it is accepted
+const magnetoBounce = 50 * time.Millisecond
+
+// MagnetoEvent is sent when the magneto setting has changed. Turning the
+// knob to START sends an event with Starter set to true. Releasing the knob
+// from START sends an event with the setting MagnetoBoth, Starter set to
+// false and the time the starter was engaged in Duration.
+type MagnetoEvent struct {
+	Setting  Magneto
+	Starter  bool
+	Duration time.Duration
+}
+
+// MagnetoController follows the magneto/starter knob of the switch panel.
+// START is a momentary starter engagement, and the knob springs back to
+// BOTH when released.
+//
+// The knob can only move between neighbouring positions. A position that is
+// not next to the previous position is caused by contact bounce and is
+// ignored, unless the panel still reports it after 50 ms, for example after
+// the knob was turned faster than the panel reports. A MagnetoEvent is sent
+// on every change.
+type MagnetoController struct {
+	panel   *SwitchPanel
+	mutex   sync.Mutex
+	setting Magneto
+	started time.Time
+	pending Magneto
+	timer   *time.Timer
+	// gen identifies the current timer, so that a confirmation of a
+	// cancelled setting that has already fired is ignored
+	gen     int
+	eventCh chan MagnetoEvent
+}
+
+// NewMagnetoController creates a new magneto controller for the switch
+// panel and starts handling its switch events. The setting is the knob
+// position, or MagnetoOff if the position is not yet known.
+func NewMagnetoController(panel *SwitchPanel) *MagnetoController {
+	m := &MagnetoController{
+		panel:   panel,
+		eventCh: make(chan MagnetoEvent, 16),
+	}
+	for id := RotOff; id <= RotStart; id++ {
+		if panel.IsSwitchSet(id) {
+			m.setting = Magneto(id - RotOff)
+		}
+	}
+	if m.setting == MagnetoStart {
+		m.started = time.Now()
+	}
+	panel.AddSwitchHandler(m)
+	return m
+}
+
+// String returns the name of the magneto setting, for example "BOTH"
+func (m Magneto) String() string {
+	switch m {
+	case MagnetoOff:
+		return "OFF"
+	case MagnetoRight:
+		return "R"
+	case MagnetoLeft:
+		return "L"
+	case MagnetoBoth:
+		return "BOTH"
+	case MagnetoStart:
+		return "START"
+	}
+	return fmt.Sprintf("Magneto(%d)", int(m))
+}
+
+// EventCh returns a channel for magneto events
+func (m *MagnetoController) EventCh() chan MagnetoEvent {
+	return m.eventCh
+}
+
+// Setting returns the magneto setting
+func (m *MagnetoController) Setting() Magneto {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return m.setting
+}
+
+// StarterEngaged returns true if the knob is held in START
+func (m *MagnetoController) StarterEngaged() bool {
+	return m.Setting() == MagnetoStart
+}
+
+// HandleSwitch handles the switch panel switch event s. It is called by the
+// panel, see AddSwitchHandler.
+func (m *MagnetoController) HandleSwitch(s SwitchState) {
+	if !s.On || s.Switch < RotOff || s.Switch > RotStart {
+		return
+	}
+	setting := Magneto(s.Switch - RotOff)
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.cancelPending()
+	d := setting - m.setting
+	switch {
+	case d == 0:
+		return
+	case d == 1 || d == -1:
+		m.set(setting)
+	default:
+		m.pending = setting
+		gen := m.gen
+		m.timer = time.AfterFunc(magnetoBounce, func() { m.confirm(gen) })
+	}
+}
+
+// confirm accepts a pending setting that is still reported by the panel.
+// gen is the generation of the timer that fired.
+func (m *MagnetoController) confirm(gen int) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if gen != m.gen || m.timer == nil {
+		return
+	}
+	m.timer = nil
+	if m.panel.IsSwitchSet(RotOff + SwitchID(m.pending)) {
+		m.set(m.pending)
+	}
+}
+
+// cancelPending ignores any pending setting. The controller mutex must be
+// held.
+func (m *MagnetoController) cancelPending() {
+	m.gen++
+	if m.timer != nil {
+		m.timer.Stop()
+		m.timer = nil
+	}
+}
+
+// set changes the setting and sends the events. The controller mutex must
+// be held.
+func (m *MagnetoController) set(setting Magneto) {
+	now := time.Now()
+	e := MagnetoEvent{Setting: setting}
+	switch {
+	case setting == MagnetoStart:
+		m.started = now
+		e.Starter = true
+	case m.setting == MagnetoStart:
+		e.Duration = now.Sub(m.started)
+	}
+	m.setting = setting
+	select {
+	case m.eventCh <- e:
+	default:
+	}
+}
@@ -0,0 +1,92 @@
+package fpanels
+
+import (
+	"testing"
+	"time"
+)
+
+// nextMagnetoEvent returns the next event of m, or false if there is none
+func nextMagnetoEvent(m *MagnetoController) (MagnetoEvent, bool) {
+	select {
+	case e := <-m.EventCh():
+		return e, true
+	default:
+		return MagnetoEvent{}, false
+	}
+}
+
+func TestMagnetoStarter(t *testing.T) {
+	panel := newTestSwitchPanel()
+	panel.switches = 1 << RotBoth
+	m := NewMagnetoController(panel)
+	if m.Setting() != MagnetoBoth {
+		t.Fatalf("Initial setting %v, want BOTH", m.Setting())
+	}
+	panel.setSwitch(RotBoth, false)
+	panel.setSwitch(RotStart, true)
+	if e, ok := nextMagnetoEvent(m); !ok || e != (MagnetoEvent{MagnetoStart, true, 0}) || !m.StarterEngaged() {
+		t.Errorf("Start event %+v, %v", e, ok)
+	}
+	time.Sleep(20 * time.Millisecond)
+	panel.setSwitch(RotStart, false)
+	panel.setSwitch(RotBoth, true)
+	e, ok := nextMagnetoEvent(m)
+	if !ok || e.Setting != MagnetoBoth || e.Starter || e.Duration < 20*time.Millisecond || m.StarterEngaged() {
+		t.Errorf("Release event %+v, %v", e, ok)
+	}
+}
+
+func TestMagnetoBounce(t *testing.T) {
+	panel := newTestSwitchPanel()
+	panel.switches = 1 << RotBoth
+	m := NewMagnetoController(panel)
+
+	// A bounce to OFF is ignored
+	panel.setSwitch(RotOff, true)
+	panel.setSwitch(RotOff, false)
+	panel.setSwitch(RotBoth, true)
+	time.Sleep(2 * magnetoBounce)
+	if e, ok := nextMagnetoEvent(m); ok || m.Setting() != MagnetoBoth {
+		t.Errorf("Bounce sent %+v and set %v", e, m.Setting())
+	}
+
+	// A fast turn to R is accepted when it is still reported
+	panel.setSwitch(RotBoth, false)
+	panel.setSwitch(RotR, true)
+	if _, ok := nextMagnetoEvent(m); ok || m.Setting() != MagnetoBoth {
+		t.Errorf("Fast turn accepted before the bounce time")
+	}
+	time.Sleep(2 * magnetoBounce)
+	if e, ok := nextMagnetoEvent(m); !ok || e != (MagnetoEvent{Setting: MagnetoRight}) || m.Setting() != MagnetoRight {
+		t.Errorf("Fast turn sent %+v, %v and set %v", e, ok, m.Setting())
+	}
+
+	// Neighbouring positions are accepted at once
+	panel.setSwitch(RotR, false)
+	panel.setSwitch(RotOff, true)
+	if e, ok := nextMagnetoEvent(m); !ok || e != (MagnetoEvent{Setting: MagnetoOff}) {
+		t.Errorf("Turn to OFF sent %+v, %v", e, ok)
+	}
+}
+
+func TestMagnetoStaleConfirm(t *testing.T) {
+	panel := newTestSwitchPanel()
+	panel.switches = 1 << RotBoth
+	m := NewMagnetoController(panel)
+	panel.setSwitch(RotBoth, false)
+	panel.setSwitch(RotOff, true)
+	m.mutex.Lock()
+	stale := m.gen
+	m.mutex.Unlock()
+	panel.setSwitch(RotOff, false)
+	panel.setSwitch(RotR, true)
+	// The confirmation of OFF runs after the knob reached R
+	m.confirm(stale)
+	if e, ok := nextMagnetoEvent(m); ok || m.Setting() != MagnetoBoth {
+		t.Errorf("Stale confirmation sent %+v and set %v", e, m.Setting())
+	}
+	time.Sleep(2 * magnetoBounce)
+	if m.Setting() != MagnetoRight {
+		t.Errorf("Setting %v after the bounce time, want R", m.Setting())
+	}
+}
@@ -3,6 +3,7 @@ package fpanels
 import (
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/google/gousb"
 )
@@ -69,21 +70,32 @@ const (
 // text on the panels. The displays are identified by the Row1 and Row2 constants.
 type MultiPanel struct {
 	panel
+	// armed are the LEDs in the LEDArmed state, guarded by the display
+	// mutex
+	armed      byte
+	armedBlink Blink
 }
 
 // NewMultiPanel creates a new instances of the Logitech/Saitek multipanel
-func NewMultiPanel() (*MultiPanel, error) {
+func NewMultiPanel(opts ...Option) (*MultiPanel, error) {
 	var err error
 	panel := MultiPanel{}
 	panel.id = Multi
+	panel.ledIndex = 10
+	panel.armedBlink = DefaultArmedBlink
 	panel.displayState = make([]byte, 12)
 	for i := range panel.displayState {
 		panel.displayState[i] = blank
 	}
 	panel.displayState[10] = 0x00
 	panel.displayState[11] = 0xff
+	panel.blankFrame = append([]byte(nil), panel.displayState...)
+	for _, opt := range opts {
+		opt(&panel.panel)
+	}
 	panel.displayDirty = true
 	panel.displayCond = sync.NewCond(&panel.displayMutex)
+	panel.epoch = time.Now()
 	panel.ctx = gousb.NewContext()
 	panel.device, err = panel.ctx.OpenDeviceWithVIDPID(USBVendorPanel, USBProductMulti)
 	if panel.device == nil || err != nil {
@@ -104,9 +116,10 @@ func NewMultiPanel() (*MultiPanel, error) {
 		panel.Close()
 		return nil, err
 	}
-	// FIX: Add WaitGroup
 	panel.switchCh = make(chan SwitchState)
+	panel.idleCh = make(chan IdleState, 4)
 	go panel.readSwitches()
+	panel.wg.Add(1)
 	go panel.refreshDisplay()
 	panel.connected = true
 	return &panel, nil
@@ -115,50 +128,59 @@ func NewMultiPanel() (*MultiPanel, error) {
 // LEDs turns on/off the LEDs given by leds. See the LED* constants.
 // For example calling
 //   panel.LEDs(LEDAP | LEDVS)
-// will turn on the AP and VS LEDs and turn off all other LEDs.
+// will turn on the AP and VS LEDs and turn off all other LEDs. Armed LEDs,
+// see SetLEDState, stop blinking.
 func (panel *MultiPanel) LEDs(leds byte) {
 	panel.displayMutex.Lock()
-	panel.displayState[10] = leds
-	panel.displayDirty = true
-	panel.displayCond.Signal()
-	panel.displayMutex.Unlock()
+	defer panel.displayMutex.Unlock()
+	panel.disarm(0xff)
+	panel.writeLEDs(leds, 0xff)
 }
 
 // LEDsOn turns on the LEDs given by leds and leaves all other LED states
 // intact. See the LED* constants. Multiple LEDs can be ORed together,
 // for example
 //   panel.LEDsOn(LEDAP | LEDVS)
+// Armed LEDs, see SetLEDState, stay on without blinking.
 func (panel *MultiPanel) LEDsOn(leds byte) {
 	panel.displayMutex.Lock()
-	panel.displayState[10] = panel.displayState[10] | leds
-	panel.displayDirty = true
-	panel.displayCond.Signal()
-	panel.displayMutex.Unlock()
+	defer panel.displayMutex.Unlock()
+	panel.disarm(leds)
+	panel.writeLEDs(leds, leds)
 }
 
 // LEDsOff turns off the LEDs given by leds and leaves all other LED states
 // intact. See the LED* constants. Multiple LEDs can be ORed together.
 // For example
 //   panel.LEDsOff(LEDAP | LEDVS)
+// Armed LEDs, see SetLEDState, are no longer armed.
 func (panel *MultiPanel) LEDsOff(leds byte) {
 	panel.displayMutex.Lock()
-	panel.displayState[10] = panel.displayState[10] & ^leds
-	panel.displayDirty = true
-	panel.displayCond.Signal()
-	panel.displayMutex.Unlock()
+	defer panel.displayMutex.Unlock()
+	panel.disarm(leds)
+	panel.writeLEDs(0, leds)
 }
 
 // LEDsOnOff turns on or off the LEDs given by leds. If val is 0 then
-// the LEDs will be turned offm else they will be turned on. All
-// other LEDs are left intact. See the LED* constants.
-// Multiple LEDs can be ORed together, for example
+// the LEDs will be turned offm else they will be turned on. If the panel
+// was created with the DimmableLEDs option, then a val between 0 and 1 sets
+// the brightness of the LEDs. All other LEDs are left intact. See the LED*
+// constants. Multiple LEDs can be ORed together, for example
 //   panel.LEDsOnOff(LEDAP | LEDVS, 1)
+// Armed LEDs, see SetLEDState, stop blinking.
 func (panel *MultiPanel) LEDsOnOff(leds byte, val float64) {
-	if val > 0 {
-		panel.LEDsOn(leds)
-	} else {
-		panel.LEDsOff(leds)
-	}
+	panel.displayMutex.Lock()
+	defer panel.displayMutex.Unlock()
+	panel.disarm(leds)
+	panel.writeLEDLevel(leds, val)
+}
+
+// BlinkLEDs makes the LEDs given by leds blink with the pattern b. The LEDs
+// only blink while they are turned on. Use NoBlink to stop blinking. For
+// example
+//   panel.BlinkLEDs(LEDAP, DefaultBlink)
+func (panel *MultiPanel) BlinkLEDs(leds byte, b Blink) {
+	panel.setBlink(10, leds, b)
 }
 
 // DisplayString displays the string given by s on the display given by
@@ -210,20 +232,18 @@ func (panel *MultiPanel) DisplayString(display DisplayID, s string) {
 		dIdx++
 	}
 
-	panel.displayMutex.Lock()
-	defer panel.displayMutex.Unlock()
-	panel.displayDirty = true
-	panel.displayCond.Signal()
+	var b [5]byte
 	dIdx--
 	// align right and fill with blanks
 	for i := 4; i >= 0; i-- {
 		if dIdx < 0 {
-			disp[i] = blank
+			b[i] = blank
 		} else {
-			disp[i] = d[dIdx]
+			b[i] = d[dIdx]
 		}
 		dIdx--
 	}
+	panel.setDisplayBytes(displayStart, b[:])
 }
 
 // DisplayInt will display the integer n on the given display
@@ -232,6 +252,204 @@ func (panel *MultiPanel) DisplayInt(display DisplayID, n int) {
 	panel.DisplayString(display, s)
 }
 
+// SetDigit sets the digit at position pos on the given display to the glyph
+// g. Positions are numbered 0-4 from the left. All other digits are left
+// intact. The multi panel can not show dots, and dashes can only be shown
+// on Row2. ErrUnsupportedGlyph is returned for such glyphs.
+func (panel *MultiPanel) SetDigit(display DisplayID, pos int, g Glyph) error {
+	if display != Row1 && display != Row2 {
+		return ErrUnknownDisplay
+	}
+	if pos < 0 || pos > 4 {
+		return ErrInvalidPosition
+	}
+	b, ok := multiGlyphByte(display, g)
+	if !ok {
+		return ErrUnsupportedGlyph
+	}
+	panel.setDisplayBytes(int(display)*5+pos, []byte{b})
+	return nil
+}
+
+// Digits returns the five glyphs currently set on the given display, or nil
+// if the display is unknown
+func (panel *MultiPanel) Digits(display DisplayID) []Glyph {
+	if display != Row1 && display != Row2 {
+		return nil
+	}
+	glyphs := make([]Glyph, 5)
+	for i, b := range panel.displayBytes(int(display)*5, 5) {
+		glyphs[i] = multiByteGlyph(b)
+	}
+	return glyphs
+}
+
+// DisplayGlyphs displays the glyphs g on the given display. At most five
+// glyphs can be given. The glyphs are aligned right and filled with blanks.
+// ErrUnsupportedGlyph is returned if the display can not show all glyphs,
+// see SetDigit.
+func (panel *MultiPanel) DisplayGlyphs(display DisplayID, g []Glyph) error {
+	b, err := multiGlyphBytes(display, g)
+	if err != nil {
+		return err
+	}
+	panel.setDisplayBytes(int(display)*5, b)
+	return nil
+}
+
+// multiGlyphBytes encodes the glyphs g right aligned as the five display
+// bytes of the given display
+func multiGlyphBytes(display DisplayID, g []Glyph) ([]byte, error) {
+	if display != Row1 && display != Row2 {
+		return nil, ErrUnknownDisplay
+	}
+	if len(g) > 5 {
+		return nil, ErrOverflow
+	}
+	b := make([]byte, 5)
+	for i := range b {
+		b[i] = blank
+	}
+	for i, glyph := range g {
+		var ok bool
+		b[5-len(g)+i], ok = multiGlyphByte(display, glyph)
+		if !ok {
+			return nil, ErrUnsupportedGlyph
+		}
+	}
+	return b, nil
+}
+
+// LayerGlyphs shows the glyphs g on the given display in the display layer
+// with the given name. See RadioPanel.LayerGlyphs. For example, to show a
+// new heading for two seconds while the encoder is turned:
+//   g, _ := FormatInt(heading, 3, Format{ZeroPad: true})
+//   panel.LayerGlyphs("turn", 1, 2*time.Second, Row1, g)
+func (panel *MultiPanel) LayerGlyphs(name string, priority int, timeout time.Duration, display DisplayID, g []Glyph) error {
+	b, err := multiGlyphBytes(display, g)
+	if err != nil {
+		return err
+	}
+	panel.setLayer(name, priority, timeout, int(display)*5, b, []byte{0xff, 0xff, 0xff, 0xff, 0xff})
+	return nil
+}
+
+// LayerLEDs sets the LEDs given by mask to the state given by leds in the
+// display layer with the given name. See RadioPanel.LayerGlyphs. LEDs not
+// in mask are not covered by the layer.
+func (panel *MultiPanel) LayerLEDs(name string, priority int, timeout time.Duration, leds byte, mask byte) {
+	panel.setLayer(name, priority, timeout, 10, []byte{leds}, []byte{mask})
+}
+
+// DisplayIntFormat displays the integer n on the given display formatted
+// according to f. ErrOverflow is returned if the number does not fit and
+// f.Overflow is OverflowError. Since Row1 can not show dashes negative
+// numbers and OverflowDashes only work on Row2.
+func (panel *MultiPanel) DisplayIntFormat(display DisplayID, n int, f Format) error {
+	g, err := FormatInt(n, 5, f)
+	if err != nil {
+		return err
+	}
+	return panel.DisplayGlyphs(display, g)
+}
+
+// BlinkDigit makes the digit at position pos on the given display blink with
+// the pattern b. Positions are numbered 0-4 from the left. Use NoBlink to
+// stop blinking.
+func (panel *MultiPanel) BlinkDigit(display DisplayID, pos int, b Blink) error {
+	if display != Row1 && display != Row2 {
+		return ErrUnknownDisplay
+	}
+	if pos < 0 || pos > 4 {
+		return ErrInvalidPosition
+	}
+	panel.setBlink(int(display)*5+pos, 0xff, b)
+	return nil
+}
+
+// BlinkDisplay makes all digits on the given display blink with the pattern
+// b. Use NoBlink to stop blinking.
+func (panel *MultiPanel) BlinkDisplay(display DisplayID, b Blink) error {
+	for pos := 0; pos < 5; pos++ {
+		if err := panel.BlinkDigit(display, pos, b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Mode returns the position of the mode selector, i.e. one of RotALT, RotVS,
+// RotIAS, RotHDG or RotCRS. RotALT is returned if the position is not yet
+// known.
+func (panel *MultiPanel) Mode() SwitchID {
+	for id := RotALT; id <= RotCRS; id++ {
+		if panel.IsSwitchSet(id) {
+			return id
+		}
+	}
+	return RotALT
+}
+
+// VisibleWidth returns the number of digits that are visible on the given
+// display when the mode selector is in position mode. When IAS, HDG or CRS is
+// selected only the three rightmost digits of Row1 are visible and Row2 is
+// blank. When ALT or VS is selected all five digits of both rows are
+// visible.
+func (panel *MultiPanel) VisibleWidth(mode SwitchID, display DisplayID) int {
+	switch {
+	case display != Row1 && display != Row2:
+		return 0
+	case mode == RotALT || mode == RotVS:
+		return 5
+	case (mode == RotIAS || mode == RotHDG || mode == RotCRS) && display == Row1:
+		return 3
+	}
+	return 0
+}
+
+// DisplayValue displays value where the panel shows it for the mode selector
+// position mode. The altitude (RotALT) is shown on Row1 and the vertical
+// speed (RotVS) on Row2, where negative values are shown with a dash. IAS,
+// HDG and CRS values are shown on the three visible digits of Row1. The
+// value is aligned right in the visible area. ErrOverflow is returned if
+// the value does not fit.
+func (panel *MultiPanel) DisplayValue(mode SwitchID, value int) error {
+	return panel.DisplayValueFormat(mode, value, Format{Overflow: OverflowError})
+}
+
+// DisplayValueFormat is like DisplayValue, but formats the value according
+// to f
+func (panel *MultiPanel) DisplayValueFormat(mode SwitchID, value int, f Format) error {
+	display := Row1
+	if mode == RotVS {
+		display = Row2
+	}
+	width := panel.VisibleWidth(mode, display)
+	if width == 0 {
+		return ErrUnknownMode
+	}
+	g, err := FormatInt(value, width, f)
+	if err != nil {
+		return err
+	}
+	return panel.DisplayGlyphs(display, g)
+}
+
+// Animate starts the LED animation a and returns its ID. See Animation. For
+// example
+//   a := Chase(LEDAP|LEDHDG|LEDNAV, 100*time.Millisecond)
+//   a.Repeat = 3
+//   panel.Animate(a)
+func (panel *MultiPanel) Animate(a Animation) AnimationID {
+	return panel.animate(10, a)
+}
+
+// StopAnimation stops the LED animation with the given ID. The LEDs return
+// to their steady state.
+func (panel *MultiPanel) StopAnimation(id AnimationID) {
+	panel.stopAnimation(id)
+}
+
 func (panel *MultiPanel) noZeroSwitch(s SwitchID) bool {
 	if s >= RotALT && s <= EncCCW {
 		return true
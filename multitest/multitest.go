@@ -18,8 +18,12 @@ func main() {
 		multiPanel.DisplayInt(fpanels.Row1, i)
 		time.Sleep(100 * time.Millisecond)
 	}
-	for i := byte(0x01); i <= 0x80; i = i << 1 {
-		multiPanel.LEDsOn(i)
-		time.Sleep(500 * time.Millisecond)
-	}
+	fill := fpanels.Fill(0xff, 500*time.Millisecond)
+	fill.Repeat = 1
+	multiPanel.Animate(fill)
+	time.Sleep(9 * 500 * time.Millisecond)
+	chase := fpanels.Chase(0xff, 100*time.Millisecond)
+	chase.Repeat = 3
+	multiPanel.Animate(chase)
+	time.Sleep(3 * 8 * 100 * time.Millisecond)
 }
@@ -5,9 +5,11 @@
 package fpanels
 
 import (
+	"bytes"
 	"errors"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/google/gousb"
 )
@@ -35,21 +37,58 @@ const (
 
 // Panel is the base struct for all panels
 type panel struct {
-	ctx          *gousb.Context
-	device       *gousb.Device
-	intf         *gousb.Interface
-	inEndpoint   *gousb.InEndpoint
-	displayState []byte
-	displayMutex sync.Mutex
-	displayCond  *sync.Cond
-	id           PanelID
-	switches     PanelSwitches
-	displayDirty bool
-	intfDone     func()
-	connected    bool
-	quit         bool
-	wg           sync.WaitGroup
-	switchCh     chan SwitchState
+	ctx           *gousb.Context
+	device        *gousb.Device
+	intf          *gousb.Interface
+	inEndpoint    *gousb.InEndpoint
+	displayState  []byte
+	blankFrame    []byte
+	displayMutex  sync.Mutex
+	displayCond   *sync.Cond
+	id            PanelID
+	switches      PanelSwitches
+	displayDirty  bool
+	blanked       bool
+	blankOnClose  bool
+	blinks        []blinker
+	layers        []*layer
+	animations    []animation
+	lastAnimation AnimationID
+	ledIndex      int
+	dimmable      bool
+	dimRate       float64
+	dimmed        byte
+	ledLevels     [8]float64
+	ledAcc        [8]float64
+	continuous    bool
+	lastFrame     time.Time
+	refreshRate   float64
+	idlePolicy    IdlePolicy
+	idle          bool
+	idleAcc       float64
+	lastActivity  time.Time
+	idleCh        chan IdleState
+	epoch         time.Time
+	wakeTimer     *time.Timer
+	intfDone      func()
+	connected     bool
+	quit          bool
+	wg            sync.WaitGroup
+	switchCh      chan SwitchState
+	handlers      []SwitchHandler
+	handlerMutex  sync.Mutex
+}
+
+// Option configures a panel. Options are given to the New*Panel()
+// functions.
+type Option func(*panel)
+
+// BlankOnClose returns an option that blanks the displays and LEDs of the
+// panel when the panel is closed
+func BlankOnClose() Option {
+	return func(panel *panel) {
+		panel.blankOnClose = true
+	}
 }
 
 // SwitchState contains the state of a switch on a panel
@@ -59,6 +98,12 @@ type SwitchState struct {
 	On     bool
 }
 
+// SwitchHandler handles switch events. See AddSwitchHandler. HandleSwitch
+// is called from the goroutine reading the switches, so it must not block.
+type SwitchHandler interface {
+	HandleSwitch(s SwitchState)
+}
+
 // PanelSwitches is the state of all switches on a panel, one bit per switch
 type PanelSwitches uint32
 
@@ -70,6 +115,19 @@ type StringDisplayer interface {
 	DisplayString(display DisplayID, s string)
 }
 
+// GlyphDisplayer provides an interface to panels with segment displays that
+// can be updated one digit at a time
+type GlyphDisplayer interface {
+	SetDigit(display DisplayID, pos int, g Glyph) error
+	Digits(display DisplayID) []Glyph
+}
+
+// Blanker provides an interface to panels that can be blanked
+type Blanker interface {
+	Blank()
+	Restore()
+}
+
 // LEDDisplayer priovides an interface to panels that has LEDs
 type LEDDisplayer interface {
 	LEDs(leds byte)
@@ -78,6 +136,14 @@ type LEDDisplayer interface {
 	LEDsOnOff(leds byte, val float64)
 }
 
+// Errors returned by the display functions
+var (
+	ErrUnknownDisplay   = errors.New("Unknown display")
+	ErrInvalidPosition  = errors.New("Invalid digit position")
+	ErrUnsupportedGlyph = errors.New("Glyph not supported by display")
+	ErrUnknownMode      = errors.New("Unknown mode")
+)
+
 // PanelIDMap maps a panel Id string to a PanelID
 var PanelIDMap = map[string]PanelID{
 	"RADIO":  Radio,
@@ -229,14 +295,14 @@ func DisplayIDString(s string) (DisplayID, error) {
 	s = strings.ToUpper(s)
 	d, ok := DisplayMap[s]
 	if !ok {
-		return 0, errors.New("Unknown display")
+		return 0, ErrUnknownDisplay
 	}
 	return d, nil
 }
 
 // IsSet returns true if the switch id is set.
 func (switches PanelSwitches) IsSet(id SwitchID) bool {
-	return uint32(switches)&1<<uint32(id) != 0
+	return uint32(switches)&(1<<uint32(id)) != 0
 }
 
 // SwitchState returns the statee of the switch with ID id, 0 or 1
@@ -263,15 +329,25 @@ func (panel *panel) readSwitches() error {
 		newState = uint32(data[0]) | uint32(data[1])<<8 | uint32(data[2])<<16
 		changed := state ^ newState
 		state = newState
+		panel.displayMutex.Lock()
 		panel.switches = PanelSwitches(state)
+		panel.touch()
+		panel.displayMutex.Unlock()
+		panel.handlerMutex.Lock()
+		handlers := panel.handlers
+		panel.handlerMutex.Unlock()
 		for i := SwitchID(0); i < 24; i++ {
 			if (changed>>i)&1 == 1 {
 				val := uint(state >> i & 1)
 				//if val == 0 && panel.noZeroSwitch(i) {
 				//	continue
 				//}
+				switchState := SwitchState{panel.ID(), i, val == 1}
+				for _, h := range handlers {
+					h.HandleSwitch(switchState)
+				}
 				select {
-				case panel.switchCh <- SwitchState{panel.ID(), i, val == 1}:
+				case panel.switchCh <- switchState:
 				default:
 				}
 			}
@@ -279,12 +355,21 @@ func (panel *panel) readSwitches() error {
 	}
 }
 
+// Close stops updating the panel and releases the USB device. If the panel
+// was created with the BlankOnClose option, then the displays and LEDs are
+// blanked before the device is released.
 func (panel *panel) Close() {
 	panel.displayMutex.Lock()
 	panel.quit = true
+	panel.scheduleWake(0)
+	panel.displayCond.Broadcast()
 	panel.displayMutex.Unlock()
+	panel.wg.Wait()
 
-	// FIX: Stop threads
+	if panel.blankOnClose && panel.connected {
+		panel.sendFrame(panel.blankFrame)
+	}
+	// FIX: Stop switch reader
 	if panel.intfDone != nil {
 		panel.intfDone()
 	}
@@ -296,34 +381,145 @@ func (panel *panel) Close() {
 	}
 }
 
+// IsSwitchSet returns true if the switch id is set
 func (panel *panel) IsSwitchSet(id SwitchID) bool {
+	panel.displayMutex.Lock()
+	defer panel.displayMutex.Unlock()
 	return panel.switches.IsSet(id)
 }
 
+// Switches returns the state of all switches on the panel
+func (panel *panel) Switches() PanelSwitches {
+	panel.displayMutex.Lock()
+	defer panel.displayMutex.Unlock()
+	return panel.switches
+}
+
 func (panel *panel) ID() PanelID {
 	return panel.id
 }
 
+// Blank turns off all displays and LEDs of the panel. The display state is
+// kept, and updates made while the panel is blanked are shown when Restore
+// is called.
+func (panel *panel) Blank() {
+	panel.displayMutex.Lock()
+	panel.blanked = true
+	panel.setDirty()
+	panel.displayMutex.Unlock()
+}
+
+// Restore shows the display state again after a call to Blank
+func (panel *panel) Restore() {
+	panel.displayMutex.Lock()
+	panel.blanked = false
+	panel.setDirty()
+	panel.displayMutex.Unlock()
+}
+
+// setDirty tells the display refresher that the display must be updated.
+// The display mutex must be held.
+func (panel *panel) setDirty() {
+	panel.displayDirty = true
+	panel.displayCond.Signal()
+}
+
+// frame renders the bytes to send to the panel at the time now into buf. It
+// returns the time until the frame changes by itself, or 0 if it only
+// changes when the display state is updated. The display mutex must be held.
+func (panel *panel) frame(buf []byte, now time.Time) time.Duration {
+	panel.continuous = false
+	idleIn := panel.checkIdle(now)
+	if panel.blanked || panel.idleBlank() {
+		copy(buf, panel.blankFrame)
+		return idleIn
+	}
+	copy(buf, panel.displayState)
+	panel.continuous = panel.applyDimming(buf)
+	next := panel.applyBlinks(buf, now)
+	next = earliest(next, panel.applyLayers(buf, now))
+	next = earliest(next, panel.applyAnimations(buf, now))
+	panel.applyIdleDim(buf)
+	return earliest(next, idleIn)
+}
+
+// sendFrame sends the display bytes in buf to the panel
+func (panel *panel) sendFrame(buf []byte) {
+	// 0x09 is REQUEST_SET_CONFIGURATION
+	// 0x0300 is:
+	// 	 0x03 HID_REPORT_TYPE_FEATURE
+	//   0x00 Report ID 0
+	panel.device.Control(gousb.ControlOut|gousb.ControlClass|gousb.ControlInterface, 0x09,
+		0x0300, 0x00, buf)
+	// FIX: Check if Control() returns an error and return it somehow or exit
+}
+
 func (panel *panel) refreshDisplay() {
+	defer panel.wg.Done()
 	tmpBuf := make([]byte, len(panel.displayState))
 	for {
 		panel.displayMutex.Lock()
-		for !panel.displayDirty {
+		for !panel.displayDirty && !panel.quit {
 			panel.displayCond.Wait()
 		}
-		// 0x09 is REQUEST_SET_CONFIGURATION
-		// 0x0300 is:
-		// 	 0x03 HID_REPORT_TYPE_FEATURE
-		//   0x00 Report ID 0
-		copy(tmpBuf, panel.displayState)
-		panel.displayDirty = false
+		if panel.quit {
+			panel.displayMutex.Unlock()
+			return
+		}
+		now := time.Now()
+		panel.measureRate(now)
+		panel.scheduleWake(panel.frame(tmpBuf, now))
+		// Keep refreshing while the frames change by themselves
+		panel.displayDirty = panel.continuous
+		continuous := panel.continuous
 		panel.displayMutex.Unlock()
-		panel.device.Control(gousb.ControlOut|gousb.ControlClass|gousb.ControlInterface, 0x09,
-			0x0300, 0x00, tmpBuf)
-		// FIX: Check if Control() returns an error and return it somehow or exit
+		panel.sendFrame(tmpBuf)
+		if d := panel.frameInterval() - time.Since(now); continuous && d > 0 {
+			time.Sleep(d)
+		}
 	}
 }
 
+// setDisplayBytes copies b to the display state starting at index start
+func (panel *panel) setDisplayBytes(start int, b []byte) {
+	panel.displayMutex.Lock()
+	panel.writeDisplay(start, b)
+	panel.displayMutex.Unlock()
+}
+
+// writeDisplay copies b to the display state starting at index start. Only
+// a write that changes the bytes is a display update, so that sending the
+// same values again does not keep the panel from becoming idle. The display
+// mutex must be held.
+func (panel *panel) writeDisplay(start int, b []byte) {
+	if bytes.Equal(panel.displayState[start:start+len(b)], b) {
+		return
+	}
+	copy(panel.displayState[start:], b)
+	panel.changed()
+}
+
+// displayBytes returns a copy of n display state bytes starting at index start
+func (panel *panel) displayBytes(start int, n int) []byte {
+	b := make([]byte, n)
+	panel.displayMutex.Lock()
+	copy(b, panel.displayState[start:start+n])
+	panel.displayMutex.Unlock()
+	return b
+}
+
+// AddSwitchHandler adds a handler for the switch events of the panel. The
+// handlers are called in the order they were added, before the event is
+// sent on the switch channel.
+func (panel *panel) AddSwitchHandler(h SwitchHandler) {
+	panel.handlerMutex.Lock()
+	// Copy the handlers since the switch reader uses the slice unlocked
+	handlers := make([]SwitchHandler, len(panel.handlers), len(panel.handlers)+1)
+	copy(handlers, panel.handlers)
+	panel.handlers = append(handlers, h)
+	panel.handlerMutex.Unlock()
+}
+
 // SwitchCh returns a channel for switch events
 func (panel *panel) SwitchCh() chan SwitchState {
 	return panel.switchCh
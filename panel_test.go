@@ -0,0 +1,143 @@
+package fpanels
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestPanelSwitchesIsSet(t *testing.T) {
+	tests := []struct {
+		switches PanelSwitches
+		id       SwitchID
+		want     bool
+	}{
+		{0, SwBat, false},
+		{1 << SwBat, SwBat, true},
+		{1 << SwBat, GearUp, false},
+		{1 << GearUp, GearUp, true},
+		{1 << GearUp, GearDown, false},
+		{1 << RotHDG, RotHDG, true},
+		{1 << RotHDG, RotALT, false},
+		{1<<SwBat | 1<<GearDown, GearDown, true},
+		{1 << 23, 23, true},
+	}
+	for _, tt := range tests {
+		if got := tt.switches.IsSet(tt.id); got != tt.want {
+			t.Errorf("PanelSwitches(%#x).IsSet(%d) = %v, want %v", uint32(tt.switches), tt.id, got, tt.want)
+		}
+		want := uint(0)
+		if tt.want {
+			want = 1
+		}
+		if got := tt.switches.SwitchState(tt.id); got != want {
+			t.Errorf("PanelSwitches(%#x).SwitchState(%d) = %d, want %d", uint32(tt.switches), tt.id, got, want)
+		}
+	}
+}
+
+// newTestMultiPanel returns a multi panel that is not connected to a device
+func newTestMultiPanel() *MultiPanel {
+	panel := &MultiPanel{}
+	panel.id = Multi
+	panel.displayState = make([]byte, 12)
+	for i := range panel.displayState {
+		panel.displayState[i] = blank
+	}
+	panel.displayState[10] = 0x00
+	panel.displayState[11] = 0xff
+	panel.blankFrame = append([]byte(nil), panel.displayState...)
+	panel.displayCond = sync.NewCond(&panel.displayMutex)
+	panel.epoch = time.Now()
+	panel.ledIndex = 10
+	panel.idleCh = make(chan IdleState, 4)
+	panel.armedBlink = DefaultArmedBlink
+	return panel
+}
+
+// newTestRadioPanel returns a radio panel that is not connected to a device
+func newTestRadioPanel() *RadioPanel {
+	panel := &RadioPanel{}
+	panel.id = Radio
+	panel.ledIndex = -1
+	panel.displayState = make([]byte, 22)
+	for i := range panel.displayState {
+		panel.displayState[i] = blank
+	}
+	panel.blankFrame = append([]byte(nil), panel.displayState...)
+	panel.displayCond = sync.NewCond(&panel.displayMutex)
+	panel.epoch = time.Now()
+	panel.idleCh = make(chan IdleState, 4)
+	return panel
+}
+
+// newTestSwitchPanel returns a switch panel that is not connected to a
+// device
+func newTestSwitchPanel() *SwitchPanel {
+	panel := &SwitchPanel{}
+	panel.id = Switch
+	panel.ledIndex = 0
+	panel.displayState = make([]byte, 1)
+	panel.blankFrame = append([]byte(nil), panel.displayState...)
+	panel.displayCond = sync.NewCond(&panel.displayMutex)
+	panel.epoch = time.Now()
+	panel.idleCh = make(chan IdleState, 4)
+	return panel
+}
+
+// setSwitch sets the switch id of the panel to on and calls the switch
+// handlers like the switch reader does
+func (panel *panel) setSwitch(id SwitchID, on bool) {
+	panel.displayMutex.Lock()
+	if on {
+		panel.switches |= 1 << id
+	} else {
+		panel.switches &^= 1 << id
+	}
+	panel.displayMutex.Unlock()
+	for _, h := range panel.handlers {
+		h.HandleSwitch(SwitchState{panel.id, id, on})
+	}
+}
+
+// render returns the frame that would be sent to the panel at the time at
+// since the panel epoch, and the time until the frame changes by itself
+func (panel *panel) render(at time.Duration) ([]byte, time.Duration) {
+	buf := make([]byte, len(panel.displayState))
+	panel.displayMutex.Lock()
+	defer panel.displayMutex.Unlock()
+	next := panel.frame(buf, panel.epoch.Add(at))
+	return buf, next
+}
+
+func TestPanelBlank(t *testing.T) {
+	panel := newTestMultiPanel()
+	panel.DisplayString(Row1, "12345")
+	panel.LEDs(LEDAP)
+	shown, _ := panel.render(0)
+	panel.Blank()
+	if f, _ := panel.render(0); !bytes.Equal(f, panel.blankFrame) {
+		t.Errorf("Blanked frame = %x, want %x", f, panel.blankFrame)
+	}
+	panel.DisplayString(Row1, "54321")
+	if f, _ := panel.render(0); !bytes.Equal(f, panel.blankFrame) {
+		t.Errorf("Frame updated while blanked = %x, want %x", f, panel.blankFrame)
+	}
+	panel.Restore()
+	f, _ := panel.render(0)
+	if bytes.Equal(f, shown) || !bytes.Equal(f, panel.displayState) {
+		t.Errorf("Restored frame = %x, want %x", f, panel.displayState)
+	}
+	if !panel.displayDirty {
+		t.Error("Restore did not mark the display dirty")
+	}
+}
+
+func TestBlankOnClose(t *testing.T) {
+	var panel panel
+	BlankOnClose()(&panel)
+	if !panel.blankOnClose {
+		t.Error("BlankOnClose option not set")
+	}
+}
@@ -0,0 +1,195 @@
+package fpanels
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"sync"
+)
+
+// ErrUnknownPreset is returned when a preset does not exist
+var ErrUnknownPreset = errors.New("Unknown preset")
+
+// Preset is a stored frequency or code, for example the ATIS frequency
+type Preset struct {
+	Name  string
+	Value int
+}
+
+// PresetBank stores named presets for each kind of radio. The presets are
+// shared by all radios of the same kind, so the NAV presets are available
+// in both the NAV1 and NAV2 positions. A PresetBank is safe for concurrent
+// use.
+//
+// A bank with a path, see SetPath, is saved to the path every time the
+// presets change. The functions that change the presets return the error
+// from saving. The presets are changed even if they could not be saved.
+//
+// Presets are saved as JSON, with the presets of each radio kind listed
+// under the name of the kind:
+//   {
+//     "COM": [{"Name": "ATIS", "Value": 118250}, {"Name": "TWR", "Value": 119100}],
+//     "NAV": [{"Name": "ILS 26", "Value": 110300}]
+//   }
+type PresetBank struct {
+	mutex   sync.Mutex
+	presets map[RadioKind][]Preset
+	path    string
+}
+
+// NewPresetBank creates a new empty preset bank
+func NewPresetBank() *PresetBank {
+	return &PresetBank{presets: make(map[RadioKind][]Preset)}
+}
+
+// LoadPresets reads a preset bank from the JSON file path. The bank is
+// saved back to the file when the presets change.
+func LoadPresets(path string) (*PresetBank, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var named map[string][]Preset
+	if err := json.Unmarshal(data, &named); err != nil {
+		return nil, err
+	}
+	b := NewPresetBank()
+	for name, presets := range named {
+		kind, err := parseRadioKind(name)
+		if err != nil {
+			return nil, err
+		}
+		b.presets[kind] = presets
+	}
+	b.path = path
+	return b, nil
+}
+
+// SetPath sets the JSON file the bank is saved to when the presets change.
+// An empty path turns off saving.
+func (b *PresetBank) SetPath(path string) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.path = path
+}
+
+// Save writes the preset bank to the JSON file path
+func (b *PresetBank) Save(path string) error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.save(path)
+}
+
+// save writes the preset bank to the JSON file path. The bank mutex must
+// be held.
+func (b *PresetBank) save(path string) error {
+	named := make(map[string][]Preset, len(b.presets))
+	for kind, presets := range b.presets {
+		named[kind.String()] = presets
+	}
+	data, err := json.MarshalIndent(named, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, data, 0644)
+}
+
+// changed saves the bank to its path, if it has one. The bank mutex must be
+// held.
+func (b *PresetBank) changed() error {
+	if b.path == "" {
+		return nil
+	}
+	return b.save(b.path)
+}
+
+// Presets returns the presets of the radio kind
+func (b *PresetBank) Presets(kind RadioKind) []Preset {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return append([]Preset(nil), b.presets[kind]...)
+}
+
+// SetPresets replaces the presets of the radio kind
+func (b *PresetBank) SetPresets(kind RadioKind, presets []Preset) error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.presets[kind] = append([]Preset(nil), presets...)
+	return b.changed()
+}
+
+// Add adds a preset to the end of the presets of the radio kind. It returns
+// the index of the new preset.
+func (b *PresetBank) Add(kind RadioKind, p Preset) (int, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.presets[kind] = append(b.presets[kind], p)
+	return len(b.presets[kind]) - 1, b.changed()
+}
+
+// Store stores the preset p at index i of the presets of the radio kind,
+// replacing the preset at i. If i is the number of presets then p is added
+// to the end. ErrUnknownPreset is returned for other indexes.
+func (b *PresetBank) Store(kind RadioKind, i int, p Preset) error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	presets := b.presets[kind]
+	switch {
+	case i == len(presets):
+		b.presets[kind] = append(presets, p)
+	case i >= 0 && i < len(presets):
+		presets[i] = p
+	default:
+		return ErrUnknownPreset
+	}
+	return b.changed()
+}
+
+// Insert inserts the preset p at index i of the presets of the radio kind,
+// moving the preset at i and the following presets one index up. If i is
+// the number of presets then p is added to the end. ErrUnknownPreset is
+// returned for other indexes.
+func (b *PresetBank) Insert(kind RadioKind, i int, p Preset) error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	presets := b.presets[kind]
+	if i < 0 || i > len(presets) {
+		return ErrUnknownPreset
+	}
+	presets = append(presets, Preset{})
+	copy(presets[i+1:], presets[i:])
+	presets[i] = p
+	b.presets[kind] = presets
+	return b.changed()
+}
+
+// Preset returns the preset at index i of the radio kind. ok is false if
+// there is no such preset.
+func (b *PresetBank) Preset(kind RadioKind, i int) (p Preset, ok bool) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	presets := b.presets[kind]
+	if i < 0 || i >= len(presets) {
+		return Preset{}, false
+	}
+	return presets[i], true
+}
+
+// Len returns the number of presets of the radio kind
+func (b *PresetBank) Len(kind RadioKind) int {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return len(b.presets[kind])
+}
+
+// parseRadioKind returns the radio kind with the given name, for example
+// "COM"
+func parseRadioKind(name string) (RadioKind, error) {
+	for kind := RadioCOM; kind <= RadioXPDR; kind++ {
+		if kind.String() == name {
+			return kind, nil
+		}
+	}
+	return 0, fmt.Errorf("Unknown radio kind %q", name)
+}
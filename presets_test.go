@@ -0,0 +1,181 @@
+package fpanels
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestPresetBank(t *testing.T) {
+	b := NewPresetBank()
+	b.SetPresets(RadioCOM, []Preset{{"ATIS", 118250}})
+	if i, err := b.Add(RadioCOM, Preset{"TWR", 119100}); i != 1 || err != nil {
+		t.Errorf("Add = %d, %v, want 1", i, err)
+	}
+	b.Add(RadioNAV, Preset{"ILS 26", 110300})
+	if p, ok := b.Preset(RadioCOM, 1); !ok || p != (Preset{"TWR", 119100}) {
+		t.Errorf("Preset(COM, 1) = %+v, %v", p, ok)
+	}
+	if _, ok := b.Preset(RadioCOM, 2); ok {
+		t.Error("Preset(COM, 2) found")
+	}
+	if err := b.Insert(RadioNAV, 0, Preset{"VOR", 113900}); err != nil {
+		t.Errorf("Insert = %v", err)
+	}
+	if err := b.Insert(RadioNAV, 3, Preset{"VOR", 113900}); err != ErrUnknownPreset {
+		t.Errorf("Insert past the end = %v, want %v", err, ErrUnknownPreset)
+	}
+	if got, want := b.Presets(RadioNAV), []Preset{{"VOR", 113900}, {"ILS 26", 110300}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Inserted presets %+v, want %+v", got, want)
+	}
+	if b.Len(RadioCOM) != 2 || b.Len(RadioADF) != 0 {
+		t.Errorf("Len = %d, %d, want 2, 0", b.Len(RadioCOM), b.Len(RadioADF))
+	}
+
+	dir, err := ioutil.TempDir("", "presets")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "presets.json")
+	if err := b.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadPresets(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, kind := range []RadioKind{RadioCOM, RadioNAV, RadioADF} {
+		if got, want := loaded.Presets(kind), b.Presets(kind); !reflect.DeepEqual(got, want) {
+			t.Errorf("Loaded %v presets = %+v, want %+v", kind, got, want)
+		}
+	}
+
+	if err := loaded.Store(RadioADF, 0, Preset{"NDB", 385}); err != nil {
+		t.Errorf("Store = %v", err)
+	}
+	if err := loaded.Store(RadioADF, 2, Preset{"NDB", 385}); err != ErrUnknownPreset {
+		t.Errorf("Store past the end = %v, want %v", err, ErrUnknownPreset)
+	}
+	if saved, err := LoadPresets(path); err != nil || saved.Len(RadioADF) != 1 {
+		t.Errorf("Stored preset not saved: %v", err)
+	}
+
+	ioutil.WriteFile(path, []byte(`{"VOR": [{"Name": "X", "Value": 1}]}`), 0644)
+	if _, err := LoadPresets(path); err == nil || err.Error() != `Unknown radio kind "VOR"` {
+		t.Errorf("LoadPresets unknown kind = %v", err)
+	}
+}
+
+// holdAct makes the ACT/STBY button of side look like it was pressed d ago
+func holdAct(c *RadioController, side RadioSide, d time.Duration) {
+	c.mutex.Lock()
+	c.act[side].pressed = time.Now().Add(-d)
+	c.mutex.Unlock()
+}
+
+func TestRadioControllerPresets(t *testing.T) {
+	panel := newTestRadioPanel()
+	c := NewRadioController(panel)
+	b := NewPresetBank()
+	b.SetPresets(RadioCOM, []Preset{{"ATIS", 118250}, {"TWR", 119100}})
+	c.SetPresets(b)
+
+	panel.setSwitch(SwAct1, true)
+	for _, want := range []int{118250, 119100, 118250} {
+		panel.setSwitch(Enc1CW1, true)
+		if e, ok := radioEvent(c); !ok || e.Standby != want || e.Active != 118000 {
+			t.Errorf("Recalled %+v, %v, want standby %d", e, ok, want)
+		}
+	}
+	panel.setSwitch(Enc1CCW1, true)
+	if e, _ := radioEvent(c); e.Standby != 119100 {
+		t.Errorf("Recalled backwards %d, want 119100", e.Standby)
+	}
+	holdAct(c, RadioSide1, 2*time.Second)
+	panel.setSwitch(SwAct1, false)
+	if e, ok := radioEvent(c); ok || c.Active(PosCOM1) != 118000 {
+		t.Errorf("Release after recall sent %+v and swapped to %d", e, c.Active(PosCOM1))
+	}
+
+	panel.setSwitch(SwAct1, true)
+	panel.setSwitch(SwAct1, false)
+	if e, ok := radioEvent(c); !ok || !e.Swapped || e.Active != 119100 {
+		t.Errorf("Short press sent %+v, %v, want swap to 119100", e, ok)
+	}
+	c.SetLongPress(time.Hour)
+	panel.setSwitch(SwAct1, true)
+	holdAct(c, RadioSide1, 2*time.Second)
+	panel.setSwitch(SwAct1, false)
+	if e, ok := radioEvent(c); !ok || !e.Swapped || e.Stored {
+		t.Errorf("Press shorter than SetLongPress sent %+v, %v, want swap", e, ok)
+	}
+}
+
+// longPressAct holds the ACT/STBY button of side 1 for a long press
+func longPressAct(panel *RadioPanel, c *RadioController) {
+	panel.setSwitch(SwAct1, true)
+	holdAct(c, RadioSide1, 2*time.Second)
+	panel.setSwitch(SwAct1, false)
+}
+
+func TestRadioControllerStorePreset(t *testing.T) {
+	panel := newTestRadioPanel()
+	c := NewRadioController(panel)
+	b := NewPresetBank()
+	b.SetPresets(RadioCOM, []Preset{{"ATIS", 118250}, {"TWR", 119100}})
+	c.SetPresets(b)
+
+	// Without a recalled preset the new preset is added to the end
+	panel.setSwitch(Enc2CW1, true)
+	radioEvent(c)
+	longPressAct(panel, c)
+	e, ok := radioEvent(c)
+	if want := (RadioEvent{PosCOM1, RadioCOM, 118000, 119000, false, 0, true, nil}); !ok || e != want {
+		t.Errorf("Store sent %+v, %v, want %+v", e, ok, want)
+	}
+	want := []Preset{{"ATIS", 118250}, {"TWR", 119100}, {"119.000", 119000}}
+	if got := b.Presets(RadioCOM); !reflect.DeepEqual(got, want) {
+		t.Errorf("Stored presets %+v, want %+v", got, want)
+	}
+
+	// After a recall the new preset is inserted after the recalled one
+	panel.setSwitch(SwAct1, true)
+	panel.setSwitch(Enc1CW1, true)
+	panel.setSwitch(SwAct1, false)
+	panel.setSwitch(Enc2CW1, true)
+	radioEvent(c)
+	if e, _ := radioEvent(c); e.Standby != 119250 {
+		t.Fatalf("Tuned recalled preset to %d, want 119250", e.Standby)
+	}
+	longPressAct(panel, c)
+	radioEvent(c)
+	want = []Preset{{"ATIS", 118250}, {"119.250", 119250}, {"TWR", 119100}, {"119.000", 119000}}
+	if got := b.Presets(RadioCOM); !reflect.DeepEqual(got, want) {
+		t.Errorf("Stored presets after recall %+v, want %+v", got, want)
+	}
+	panel.setSwitch(SwAct1, true)
+	panel.setSwitch(Enc1CW1, true)
+	panel.setSwitch(SwAct1, false)
+	if e, _ := radioEvent(c); e.Standby != 119100 {
+		t.Errorf("Recalled %d after the stored preset, want 119100", e.Standby)
+	}
+
+	// The preset is stored even if the bank cannot be saved
+	dir, err := ioutil.TempDir("", "presets")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	b.SetPath(filepath.Join(dir, "missing", "presets.json"))
+	longPressAct(panel, c)
+	if e, ok := radioEvent(c); !ok || !e.Stored || e.Err == nil {
+		t.Errorf("Store with failing save sent %+v, %v, want an error", e, ok)
+	}
+	if n := b.Len(RadioCOM); n != 5 {
+		t.Errorf("%d presets after failed save, want 5", n)
+	}
+}
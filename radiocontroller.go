@@ -0,0 +1,366 @@
+package fpanels
+
+import (
+	"time"
+)
+
+// RadioSide is the upper or the lower half of the radio panel, each with a
+// function selector, a dual rotary encoder, an ACT/STBY button and an
+// active and a standby display
+type RadioSide int
+
+// Radio panel sides
+const (
+	RadioSide1 RadioSide = iota
+	RadioSide2
+)
+
+// RadioPosition is a position of the radio panel function selectors
+type RadioPosition int
+
+// Radio panel function selector positions
+const (
+	PosCOM1 RadioPosition = iota
+	PosCOM2
+	PosNAV1
+	PosNAV2
+	PosADF
+	PosDME
+	PosXPDR
+)
+
+// RadioInput is an input from the encoders and the ACT/STBY button on one
+// side of the radio panel
+type RadioInput int
+
+// Radio panel inputs
+const (
+	RadioInnerCW RadioInput = iota
+	RadioInnerCCW
+	RadioOuterCW
+	RadioOuterCCW
+	RadioActPressed
+	RadioActReleased
+)
+
+// RadioEvent is sent when a radio has been tuned or swapped with the radio
+// panel
+type RadioEvent struct {
+	Position RadioPosition
+	Kind     RadioKind
+	Active   int
+	Standby  int
+	// Swapped is true if the active value was set from the standby value,
+	// by swapping them or by squawking a transponder code, and false if
+	// standby was tuned
+	Swapped bool
+	// Mode is the transponder mode of XPDR radios
+	Mode TransponderMode
+	// Stored is true if the standby value was stored as a preset, see
+	// RadioController
+	Stored bool
+	// Err is the error from saving the preset bank after a preset was
+	// stored, or nil
+	Err error
+}
+
+// RadioController tunes radios with the radio panel. The selectors choose
+// which radio is shown on each side of the panel, see RadioMemory. The
+// outer knob changes the MHz and the inner knob the kHz of the standby
+// frequency, and the ACT/STBY button swaps the active and standby
+// frequencies. See RadioModel.TuneCoarse and RadioModel.TuneFine for how
+// other radios are tuned. The XPDR position is shown with a
+// TransponderEditor. The displays are updated and a RadioEvent is sent on
+// every change.
+//
+// With a preset bank, see SetPresets, the frequencies are swapped when the
+// ACT/STBY button is released instead of when it is pressed. Turning the
+// inner knob while holding the button cycles through the presets of the
+// radio on the standby display. Holding the button for the long press time,
+// see SetLongPress, without turning the knob inserts the standby value as a
+// preset after the last recalled or stored preset, or at the end if no
+// preset has been recalled. The button then does not swap the frequencies.
+// The bank is saved if it has a path, and a RadioEvent with Stored set
+// reports any error from saving it.
+type RadioController struct {
+	*RadioMemory
+	xpdr    *TransponderEditor
+	presets *PresetBank
+	// slots are the indexes of the last recalled or stored presets, or -1
+	slots     [7]int
+	act       [2]actState
+	longPress time.Duration
+	eventCh   chan RadioEvent
+}
+
+// actState is the state of the ACT/STBY button on one side
+type actState struct {
+	pressed time.Time
+	// used is true if the knob was turned while the button was held
+	used bool
+}
+
+// NewRadioController creates a new radio controller for the radio panel
+// and starts handling its switch events
+func NewRadioController(panel *RadioPanel) *RadioController {
+	c := &RadioController{
+		RadioMemory: newRadioMemory(panel),
+		longPress:   DefaultLongPress,
+		eventCh:     make(chan RadioEvent, 16),
+	}
+	for i := range c.slots {
+		c.slots[i] = -1
+	}
+	c.xpdr = NewTransponderEditor(c.radios[PosXPDR])
+	c.SetWidget(PosXPDR, c.xpdr)
+	panel.AddSwitchHandler(c)
+	return c
+}
+
+// EventCh returns a channel for radio events
+func (c *RadioController) EventCh() chan RadioEvent {
+	return c.eventCh
+}
+
+// TransponderMode returns the mode of the transponder
+func (c *RadioController) TransponderMode() TransponderMode {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.xpdr.Mode()
+}
+
+// SetTransponderMode sets the mode of the transponder, for example when it
+// has been changed by a simulator
+func (c *RadioController) SetTransponderMode(m TransponderMode) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.xpdr.SetMode(m)
+	c.paintPosition(PosXPDR)
+}
+
+// SetVFRCode sets the transponder code squawked by a long press of the
+// ACT/STBY button, see TransponderEditor.SetVFRCode
+func (c *RadioController) SetVFRCode(code int) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.xpdr.SetVFRCode(code)
+}
+
+// SetLongPress sets the time the ACT/STBY button must be held to store a
+// preset or to squawk the VFR code
+func (c *RadioController) SetLongPress(d time.Duration) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.longPress = d
+	c.xpdr.SetLongPress(d)
+}
+
+// SetPresets sets the preset bank recalled with the ACT/STBY button. A nil
+// bank turns off presets.
+func (c *RadioController) SetPresets(b *PresetBank) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.presets = b
+	for i := range c.slots {
+		c.slots[i] = -1
+	}
+}
+
+// HandleSwitch handles the radio panel switch event s. It is called by the
+// panel, see AddSwitchHandler.
+func (c *RadioController) HandleSwitch(s SwitchState) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.handleSelector(s) {
+		return
+	}
+	side, in, ok := radioInput(s)
+	if !ok {
+		return
+	}
+	pos := c.positions[side]
+	r := c.radios[pos]
+	if w := c.widgets[pos]; w != nil {
+		before := c.event(pos, false)
+		if !w.Input(in, time.Now()) {
+			return
+		}
+		c.paintPosition(pos)
+		// Moving the cursor of a widget changes nothing to report, but
+		// squawking the same code again does
+		squawk := w == RadioWidget(c.xpdr) && in == RadioActReleased
+		after := c.event(pos, r.Active() != before.Active || squawk)
+		if after != before {
+			c.send(after)
+		}
+		return
+	}
+	act := &c.act[side]
+	held := !act.pressed.IsZero() && c.presets != nil
+	switch in {
+	case RadioInnerCW, RadioInnerCCW:
+		n := 1
+		if in == RadioInnerCCW {
+			n = -1
+		}
+		if held {
+			act.used = true
+			c.recallPreset(pos, n)
+		} else {
+			r.TuneFine(n)
+		}
+	case RadioOuterCW:
+		r.TuneCoarse(1)
+	case RadioOuterCCW:
+		r.TuneCoarse(-1)
+	case RadioActPressed:
+		if c.presets == nil {
+			r.Swap()
+			break
+		}
+		*act = actState{pressed: time.Now()}
+		return
+	case RadioActReleased:
+		if !held {
+			*act = actState{}
+			return
+		}
+		long := time.Since(act.pressed) >= c.longPress
+		used := act.used
+		*act = actState{}
+		if used {
+			return
+		}
+		if long {
+			e := c.event(pos, false)
+			e.Stored = true
+			e.Err = c.storePreset(pos)
+			c.send(e)
+			return
+		}
+		r.Swap()
+	default:
+		return
+	}
+	c.paintPosition(pos)
+	c.sendEvent(pos, in == RadioActPressed || in == RadioActReleased)
+}
+
+// recallPreset sets the standby value of the radio at the selector
+// position pos to the preset n steps from the last recalled preset. The
+// controller mutex must be held.
+func (c *RadioController) recallPreset(pos RadioPosition, n int) {
+	r := c.radios[pos]
+	count := c.presets.Len(r.Kind())
+	if count == 0 {
+		return
+	}
+	slot := c.slots[pos] + n
+	if c.slots[pos] < 0 && n < 0 {
+		slot = n
+	}
+	c.slots[pos] = wrap(slot, count)
+	if p, ok := c.presets.Preset(r.Kind(), c.slots[pos]); ok {
+		r.SetStandby(p.Value)
+	}
+}
+
+// storePreset inserts the standby value of the radio at the selector
+// position pos, named by the value, after the last recalled or stored
+// preset, or at the end if there is none. It returns the error from saving
+// the bank. The controller mutex must be held.
+func (c *RadioController) storePreset(pos RadioPosition) error {
+	r := c.radios[pos]
+	p := Preset{Name: r.ValueString(r.Standby()), Value: r.Standby()}
+	n := c.presets.Len(r.Kind())
+	slot := c.slots[pos] + 1
+	if c.slots[pos] < 0 || slot > n {
+		slot = n
+	}
+	c.slots[pos] = slot
+	// The preset is inserted even if the bank could not be saved
+	return c.presets.Insert(r.Kind(), slot, p)
+}
+
+// sendEvent sends a radio event for the radio at the selector position pos.
+// The controller mutex must be held.
+func (c *RadioController) sendEvent(pos RadioPosition, swapped bool) {
+	c.send(c.event(pos, swapped))
+}
+
+// event returns a radio event for the radio at the selector position pos.
+// The controller mutex must be held.
+func (c *RadioController) event(pos RadioPosition, swapped bool) RadioEvent {
+	r := c.radios[pos]
+	var mode TransponderMode
+	if r.Kind() == RadioXPDR {
+		mode = c.xpdr.Mode()
+	}
+	return RadioEvent{pos, r.Kind(), r.Active(), r.Standby(), swapped, mode, false, nil}
+}
+
+// send sends the radio event e if there is room in the event channel
+func (c *RadioController) send(e RadioEvent) {
+	select {
+	case c.eventCh <- e:
+	default:
+	}
+}
+
+// displays returns the active and standby displays of the side
+func (side RadioSide) displays() (active, standby DisplayID) {
+	if side == RadioSide2 {
+		return Display2Active, Display2Standby
+	}
+	return Display1Active, Display1Standby
+}
+
+// radioPosition returns the current selector position of the given side.
+// PosCOM1 is returned if the position is not yet known.
+func (panel *RadioPanel) radioPosition(side RadioSide) RadioPosition {
+	first := Rot1COM1
+	if side == RadioSide2 {
+		first = Rot2Com1
+	}
+	for pos := PosCOM1; pos <= PosXPDR; pos++ {
+		if panel.IsSwitchSet(first + SwitchID(pos)) {
+			return pos
+		}
+	}
+	return PosCOM1
+}
+
+// selectorPosition returns the side and selector position of the radio
+// panel switch id. ok is false if id is not a selector switch.
+func selectorPosition(id SwitchID) (side RadioSide, pos RadioPosition, ok bool) {
+	switch {
+	case id >= Rot1COM1 && id <= Rot1XPDR:
+		return RadioSide1, RadioPosition(id - Rot1COM1), true
+	case id >= Rot2Com1 && id <= Rot2XPDR:
+		return RadioSide2, RadioPosition(id - Rot2Com1), true
+	}
+	return 0, 0, false
+}
+
+// radioInput returns the side and input of the radio panel switch event s.
+// The encoders give one event when they turn on for every detent. ok is
+// false if s is not an encoder or button input.
+func radioInput(s SwitchState) (side RadioSide, in RadioInput, ok bool) {
+	switch s.Switch {
+	case SwAct1, SwAct2:
+		if s.Switch == SwAct2 {
+			side = RadioSide2
+		}
+		if s.On {
+			return side, RadioActPressed, true
+		}
+		return side, RadioActReleased, true
+	}
+	if !s.On || s.Switch < Enc1CW1 || s.Switch > Enc2CCW2 {
+		return 0, 0, false
+	}
+	// Enc1CW1, Enc1CCW1, Enc2CW1, Enc2CCW1 are the inner and outer encoder
+	// of side 1, followed by the same for side 2
+	i := s.Switch - Enc1CW1
+	return RadioSide(i / 4), RadioInput(i % 4), true
+}
@@ -0,0 +1,74 @@
+package fpanels
+
+import "testing"
+
+// radioEvent returns the next radio event of c, or false if there is none
+func radioEvent(c *RadioController) (RadioEvent, bool) {
+	select {
+	case e := <-c.EventCh():
+		return e, true
+	default:
+		return RadioEvent{}, false
+	}
+}
+
+func TestRadioController(t *testing.T) {
+	panel := newTestRadioPanel()
+	panel.setSwitch(Rot2NAV1, true)
+	c := NewRadioController(panel)
+	if c.Position(RadioSide1) != PosCOM1 || c.Position(RadioSide2) != PosNAV1 {
+		t.Fatalf("Positions = %v, %v, want %v, %v", c.Position(RadioSide1), c.Position(RadioSide2), PosCOM1, PosNAV1)
+	}
+	steps := []struct {
+		id      SwitchID
+		on      bool
+		event   bool
+		want    RadioEvent
+		display DisplayID
+		digits  string
+	}{
+		{Enc2CW1, true, true, RadioEvent{PosCOM1, RadioCOM, 118000, 119000, false, 0, false, nil}, Display1Standby, "|1|9.|0|0|0|"},
+		{Enc2CW1, false, false, RadioEvent{}, Display1Standby, "|1|9.|0|0|0|"},
+		{Enc1CW1, true, true, RadioEvent{PosCOM1, RadioCOM, 118000, 119025, false, 0, false, nil}, Display1Standby, "|1|9.|0|2|5|"},
+		{SwAct1, true, true, RadioEvent{PosCOM1, RadioCOM, 119025, 118000, true, 0, false, nil}, Display1Active, "|1|9.|0|2|5|"},
+		{SwAct1, false, false, RadioEvent{}, Display1Standby, "|1|8.|0|0|0|"},
+		{Enc1CCW2, true, true, RadioEvent{PosNAV1, RadioNAV, 108000, 108950, false, 0, false, nil}, Display2Standby, "|1|0|8.|9|5|"},
+		{Enc2CCW2, true, true, RadioEvent{PosNAV1, RadioNAV, 108000, 117950, false, 0, false, nil}, Display2Standby, "|1|1|7.|9|5|"},
+		{Rot1ADF, true, false, RadioEvent{}, Display1Active, "| | |1|9|0|"},
+		{Enc2CW1, true, true, RadioEvent{PosADF, RadioADF, 190, 290, false, 0, false, nil}, Display1Standby, "| | |2|9|0|"},
+	}
+	for _, s := range steps {
+		panel.setSwitch(s.id, s.on)
+		e, ok := radioEvent(c)
+		if ok != s.event || e != s.want {
+			t.Errorf("Switch %d %v: event %+v, %v, want %+v, %v", s.id, s.on, e, ok, s.want, s.event)
+		}
+		if g := glyphsString(panel.Digits(s.display)); g != s.digits {
+			t.Errorf("Switch %d %v: display %d = %s, want %s", s.id, s.on, s.display, g, s.digits)
+		}
+	}
+}
+
+func TestRadioInput(t *testing.T) {
+	tests := []struct {
+		s    SwitchState
+		side RadioSide
+		in   RadioInput
+		ok   bool
+	}{
+		{SwitchState{Radio, Enc1CW1, true}, RadioSide1, RadioInnerCW, true},
+		{SwitchState{Radio, Enc1CCW1, true}, RadioSide1, RadioInnerCCW, true},
+		{SwitchState{Radio, Enc2CW1, true}, RadioSide1, RadioOuterCW, true},
+		{SwitchState{Radio, Enc2CCW2, true}, RadioSide2, RadioOuterCCW, true},
+		{SwitchState{Radio, Enc2CCW2, false}, 0, 0, false},
+		{SwitchState{Radio, SwAct2, true}, RadioSide2, RadioActPressed, true},
+		{SwitchState{Radio, SwAct1, false}, RadioSide1, RadioActReleased, true},
+		{SwitchState{Radio, Rot1COM1, true}, 0, 0, false},
+	}
+	for _, tt := range tests {
+		side, in, ok := radioInput(tt.s)
+		if side != tt.side || in != tt.in || ok != tt.ok {
+			t.Errorf("radioInput(%+v) = %v, %v, %v, want %v, %v, %v", tt.s, side, in, ok, tt.side, tt.in, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,193 @@
+package fpanels
+
+import (
+	"sync"
+	"time"
+)
+
+// RadioWidget shows a selector position in another way than as the active
+// and standby values of its radio, for example a TransponderEditor. The
+// widget is called with the memory mutex held.
+type RadioWidget interface {
+	// Input handles an input from a side where the position is selected at
+	// the time now. It returns true if the displays must be updated.
+	Input(in RadioInput, now time.Time) bool
+	// Glyphs returns the glyphs of the active and standby displays
+	Glyphs() (active, standby []Glyph)
+}
+
+// RadioMemory remembers the active and standby values of the radio at each
+// radio panel selector position, and shows the values of the selected
+// radios on the displays. When a selector is moved, the displays of that
+// side are updated with the values of the new position. Use the memory to
+// show radio values from a simulator without tuning them with the panel,
+// or use a RadioController to also tune them.
+//
+// Both sides of the panel share the same seven radios, so selecting COM1 on
+// both sides shows the same values.
+type RadioMemory struct {
+	panel     *RadioPanel
+	mutex     sync.Mutex
+	radios    [7]*RadioModel
+	widgets   [7]RadioWidget
+	positions [2]RadioPosition
+}
+
+// NewRadioMemory creates a new radio memory for the radio panel and starts
+// following its selectors
+func NewRadioMemory(panel *RadioPanel) *RadioMemory {
+	m := newRadioMemory(panel)
+	panel.AddSwitchHandler(m)
+	return m
+}
+
+// newRadioMemory creates a new radio memory without adding it as a switch
+// handler
+func newRadioMemory(panel *RadioPanel) *RadioMemory {
+	m := &RadioMemory{panel: panel}
+	kinds := [7]RadioKind{RadioCOM, RadioCOM, RadioNAV, RadioNAV, RadioADF, RadioDME, RadioXPDR}
+	for i, kind := range kinds {
+		m.radios[i] = NewRadioModel(kind)
+	}
+	for side := RadioSide1; side <= RadioSide2; side++ {
+		m.positions[side] = panel.radioPosition(side)
+	}
+	m.Repaint()
+	return m
+}
+
+// Position returns the selector position of the given side
+func (m *RadioMemory) Position(side RadioSide) RadioPosition {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return m.positions[side]
+}
+
+// Active returns the active value of the radio at the selector position pos
+func (m *RadioMemory) Active(pos RadioPosition) int {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return m.radios[pos].Active()
+}
+
+// Standby returns the standby value of the radio at the selector position
+// pos
+func (m *RadioMemory) Standby(pos RadioPosition) int {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return m.radios[pos].Standby()
+}
+
+// Set sets the active and standby values of the radio at the selector
+// position pos, for example when they have been changed by a simulator.
+// ErrInvalidValue is returned, and nothing is changed, if any of the
+// values is not valid for the radio.
+func (m *RadioMemory) Set(pos RadioPosition, active, standby int) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	r := m.radios[pos]
+	if !r.Valid(active) || !r.Valid(standby) {
+		return ErrInvalidValue
+	}
+	r.SetActive(active)
+	r.SetStandby(standby)
+	m.paintPosition(pos)
+	return nil
+}
+
+// SetActive sets the active value of the radio at the selector position
+// pos
+func (m *RadioMemory) SetActive(pos RadioPosition, v int) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if err := m.radios[pos].SetActive(v); err != nil {
+		return err
+	}
+	m.paintPosition(pos)
+	return nil
+}
+
+// SetStandby sets the standby value of the radio at the selector position
+// pos
+func (m *RadioMemory) SetStandby(pos RadioPosition, v int) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if err := m.radios[pos].SetStandby(v); err != nil {
+		return err
+	}
+	m.paintPosition(pos)
+	return nil
+}
+
+// SetChannelSpacing sets the channel spacing of the COM radio at the
+// selector position pos
+func (m *RadioMemory) SetChannelSpacing(pos RadioPosition, s ChannelSpacing) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.radios[pos].SetChannelSpacing(s)
+	m.paintPosition(pos)
+}
+
+// SetWidget shows the selector position pos with the widget w. A nil w shows
+// the active and standby values of the radio again.
+func (m *RadioMemory) SetWidget(pos RadioPosition, w RadioWidget) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.widgets[pos] = w
+	m.paintPosition(pos)
+}
+
+// Repaint shows the selected radios on the displays again
+func (m *RadioMemory) Repaint() {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.paint(RadioSide1)
+	m.paint(RadioSide2)
+}
+
+// HandleSwitch handles the radio panel switch event s. It is called by the
+// panel, see AddSwitchHandler.
+func (m *RadioMemory) HandleSwitch(s SwitchState) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.handleSelector(s)
+}
+
+// handleSelector updates the selector position and repaints the side if s
+// is a selector event. It returns true if s is a selector event. The
+// memory mutex must be held.
+func (m *RadioMemory) handleSelector(s SwitchState) bool {
+	side, pos, ok := selectorPosition(s.Switch)
+	if !ok {
+		return false
+	}
+	if s.On {
+		m.positions[side] = pos
+		m.paint(side)
+	}
+	return true
+}
+
+// paintPosition shows the radio at the selector position pos on the sides
+// where it is selected. The memory mutex must be held.
+func (m *RadioMemory) paintPosition(pos RadioPosition) {
+	for side := RadioSide1; side <= RadioSide2; side++ {
+		if m.positions[side] == pos {
+			m.paint(side)
+		}
+	}
+}
+
+// paint shows the selected radio or widget on the displays of the given
+// side. The memory mutex must be held.
+func (m *RadioMemory) paint(side RadioSide) {
+	active, standby := side.displays()
+	pos := m.positions[side]
+	if w := m.widgets[pos]; w != nil {
+		a, s := w.Glyphs()
+		m.panel.DisplayGlyphs(active, a)
+		m.panel.DisplayGlyphs(standby, s)
+		return
+	}
+	m.panel.DisplayRadio(m.radios[pos], active, standby)
+}
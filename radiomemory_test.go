@@ -0,0 +1,44 @@
+package fpanels
+
+import "testing"
+
+func TestRadioMemory(t *testing.T) {
+	panel := newTestRadioPanel()
+	m := NewRadioMemory(panel)
+	if err := m.Set(PosNAV1, 110500, 113900); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Set(PosNAV1, 110500, 118000); err != ErrInvalidValue {
+		t.Errorf("Set invalid standby = %v, want %v", err, ErrInvalidValue)
+	}
+	steps := []struct {
+		name     string
+		do       func()
+		displays [4]string
+	}{
+		{"start", func() {}, [4]string{"|1|8.|0|0|0|", "|1|8.|0|0|0|", "|1|8.|0|0|0|", "|1|8.|0|0|0|"}},
+		{"side 1 NAV1", func() { panel.setSwitch(Rot1NAV1, true) },
+			[4]string{"|1|1|0.|5|0|", "|1|1|3.|9|0|", "|1|8.|0|0|0|", "|1|8.|0|0|0|"}},
+		{"side 2 NAV1", func() { panel.setSwitch(Rot2NAV1, true) },
+			[4]string{"|1|1|0.|5|0|", "|1|1|3.|9|0|", "|1|1|0.|5|0|", "|1|1|3.|9|0|"}},
+		{"set standby", func() { m.SetStandby(PosNAV1, 114000) },
+			[4]string{"|1|1|0.|5|0|", "|1|1|4.|0|0|", "|1|1|0.|5|0|", "|1|1|4.|0|0|"}},
+		{"set unselected", func() { m.SetActive(PosCOM2, 121500) },
+			[4]string{"|1|1|0.|5|0|", "|1|1|4.|0|0|", "|1|1|0.|5|0|", "|1|1|4.|0|0|"}},
+		{"side 1 COM2", func() { panel.setSwitch(Rot1COM2, true) },
+			[4]string{"|2|1.|5|0|0|", "|1|8.|0|0|0|", "|1|1|0.|5|0|", "|1|1|4.|0|0|"}},
+		{"side 1 NAV1 again", func() { panel.setSwitch(Rot1NAV1, true) },
+			[4]string{"|1|1|0.|5|0|", "|1|1|4.|0|0|", "|1|1|0.|5|0|", "|1|1|4.|0|0|"}},
+	}
+	for _, s := range steps {
+		s.do()
+		for i, want := range s.displays {
+			if g := glyphsString(panel.Digits(Display1Active + DisplayID(i))); g != want {
+				t.Errorf("%s: display %d = %s, want %s", s.name, i, g, want)
+			}
+		}
+	}
+	if m.Position(RadioSide1) != PosNAV1 || m.Active(PosCOM2) != 121500 || m.Standby(PosNAV1) != 114000 {
+		t.Errorf("Memory = %v, %d, %d, want %v, 121500, 114000", m.Position(RadioSide1), m.Active(PosCOM2), m.Standby(PosNAV1), PosNAV1)
+	}
+}
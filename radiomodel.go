@@ -0,0 +1,301 @@
+package fpanels
+
+import (
+	"errors"
+	"fmt"
+)
+
+// RadioKind is the kind of a radio
+type RadioKind int
+
+// Radio kinds
+const (
+	RadioCOM RadioKind = iota
+	RadioNAV
+	RadioADF
+	RadioDME
+	RadioXPDR
+)
+
+// ChannelSpacing is the channel spacing of COM radios
+type ChannelSpacing int
+
+// Channel spacings
+const (
+	Spacing25kHz ChannelSpacing = iota
+	Spacing8_33kHz
+)
+
+// ErrInvalidValue is returned when a frequency or code is not valid for a
+// radio
+var ErrInvalidValue = errors.New("Invalid radio value")
+
+// RadioModel is a radio with an active and a standby frequency. Frequencies
+// are given in kHz, for example 118250 for 118.250 MHz. For transponders
+// the value is the four digit octal code written as a decimal number, for
+// example 7000.
+//
+// The radios have the following bounds and channel spacing:
+//
+// - COM: 118.000-136.975 MHz at 25 kHz, or 118.000-136.990 MHz with 8.33 kHz
+// spacing. 8.33 kHz channels are given by their channel names, for example
+// 118.005 and 118.010.
+//
+// - NAV and DME: 108.00-117.95 MHz at 50 kHz
+//
+// - ADF: 190-1750 kHz at 1 kHz
+//
+// - XPDR: 0000-7777, each digit 0-7
+//
+// A RadioModel is not safe for concurrent use.
+type RadioModel struct {
+	kind    RadioKind
+	spacing ChannelSpacing
+	active  int
+	standby int
+}
+
+// NewRadioModel creates a new radio of the given kind. COM radios use 25
+// kHz channel spacing. The active and standby values are set to the lowest
+// valid value.
+func NewRadioModel(kind RadioKind) *RadioModel {
+	r := &RadioModel{kind: kind}
+	r.active = r.min()
+	r.standby = r.active
+	return r
+}
+
+// String returns the name of the radio kind, for example "COM"
+func (kind RadioKind) String() string {
+	switch kind {
+	case RadioCOM:
+		return "COM"
+	case RadioNAV:
+		return "NAV"
+	case RadioADF:
+		return "ADF"
+	case RadioDME:
+		return "DME"
+	case RadioXPDR:
+		return "XPDR"
+	}
+	return fmt.Sprintf("RadioKind(%d)", int(kind))
+}
+
+// Kind returns the kind of the radio
+func (r *RadioModel) Kind() RadioKind {
+	return r.kind
+}
+
+// SetChannelSpacing sets the channel spacing of a COM radio. When going
+// from 8.33 kHz to 25 kHz spacing the frequencies are rounded down to the
+// nearest 25 kHz channel.
+func (r *RadioModel) SetChannelSpacing(s ChannelSpacing) {
+	if r.kind != RadioCOM {
+		return
+	}
+	r.spacing = s
+	if s == Spacing25kHz {
+		r.active -= r.active % 25
+		r.standby -= r.standby % 25
+	}
+}
+
+// Active returns the active frequency or code
+func (r *RadioModel) Active() int {
+	return r.active
+}
+
+// Standby returns the standby frequency or code
+func (r *RadioModel) Standby() int {
+	return r.standby
+}
+
+// SetActive sets the active frequency or code. ErrInvalidValue is returned
+// if v is not valid for the radio.
+func (r *RadioModel) SetActive(v int) error {
+	if !r.Valid(v) {
+		return ErrInvalidValue
+	}
+	r.active = v
+	return nil
+}
+
+// SetStandby sets the standby frequency or code. ErrInvalidValue is
+// returned if v is not valid for the radio.
+func (r *RadioModel) SetStandby(v int) error {
+	if !r.Valid(v) {
+		return ErrInvalidValue
+	}
+	r.standby = v
+	return nil
+}
+
+// Swap swaps the active and standby values
+func (r *RadioModel) Swap() {
+	r.active, r.standby = r.standby, r.active
+}
+
+// Valid returns true if v is a valid frequency or code for the radio
+func (r *RadioModel) Valid(v int) bool {
+	switch r.kind {
+	case RadioCOM:
+		return v >= 118000 && v < 137000 && r.comChannelValid(v%1000)
+	case RadioNAV, RadioDME:
+		return v >= 108000 && v < 118000 && v%50 == 0
+	case RadioADF:
+		return v >= 190 && v <= 1750
+	case RadioXPDR:
+		return v >= 0 && v <= 7777 && octalDigits(v)
+	}
+	return false
+}
+
+// TuneCoarse changes the standby value n steps with the outer knob of a
+// radio. COM, NAV and DME radios change MHz, and wrap around within the
+// band. ADF radios change the hundreds of kHz, keeping the lower digits, and
+// XPDR radios change the two first digits.
+func (r *RadioModel) TuneCoarse(n int) {
+	switch r.kind {
+	case RadioCOM:
+		r.standby = wrap(r.standby/1000-118+n, 19)*1000 + 118000 + r.standby%1000
+	case RadioNAV, RadioDME:
+		r.standby = wrap(r.standby/1000-108+n, 10)*1000 + 108000 + r.standby%1000
+	case RadioADF:
+		r.standby = adfHundreds(r.standby, n)
+	case RadioXPDR:
+		r.standby = octalPair(r.standby/100, n)*100 + r.standby%100
+	}
+}
+
+// TuneFine changes the standby value n channels with the inner knob of a
+// radio. COM, NAV and DME radios change kHz and wrap around within the MHz.
+// ADF radios change 1 kHz and XPDR radios change the two last digits.
+func (r *RadioModel) TuneFine(n int) {
+	switch r.kind {
+	case RadioCOM:
+		khz := r.standby % 1000
+		if r.spacing == Spacing8_33kHz {
+			// Four channel names per 25 kHz: .000, .005, .010 and .015
+			i := wrap(khz/25*4+khz%25/5+n, 160)
+			khz = i/4*25 + i%4*5
+		} else {
+			khz = wrap(khz/25+n, 40) * 25
+		}
+		r.standby = r.standby - r.standby%1000 + khz
+	case RadioNAV, RadioDME:
+		r.standby = r.standby - r.standby%1000 + wrap(r.standby%1000/50+n, 20)*50
+	case RadioADF:
+		r.standby = wrap(r.standby-190+n, 1750-190+1) + 190
+	case RadioXPDR:
+		r.standby = r.standby - r.standby%100 + octalPair(r.standby%100, n)
+	}
+}
+
+// Glyphs returns v as shown on a five digit radio panel display. COM
+// frequencies are shown without the leading 1, for example "18.250" for
+// 118.250 MHz. NAV and DME frequencies are shown as "108.00", ADF
+// frequencies as " 1750" and XPDR codes as " 7000".
+func (r *RadioModel) Glyphs(v int) []Glyph {
+	var g []Glyph
+	switch r.kind {
+	case RadioCOM:
+		g, _ = FormatInt(v%100000, 5, Format{ZeroPad: true})
+		g[1] = g[1].WithDot()
+	case RadioNAV, RadioDME:
+		g, _ = FormatInt(v/10, 5, Format{ZeroPad: true})
+		g[2] = g[2].WithDot()
+	case RadioADF:
+		g, _ = FormatInt(v, 5, Format{})
+	default:
+		g, _ = FormatInt(v, 4, Format{ZeroPad: true})
+		g = append([]Glyph{GlyphBlank}, g...)
+	}
+	return g
+}
+
+// ValueString returns v formatted as a frequency or code, for example
+// "118.250", "108.00", "1750" or "7000"
+func (r *RadioModel) ValueString(v int) string {
+	switch r.kind {
+	case RadioCOM:
+		return fmt.Sprintf("%d.%03d", v/1000, v%1000)
+	case RadioNAV, RadioDME:
+		return fmt.Sprintf("%d.%02d", v/1000, v%1000/10)
+	case RadioXPDR:
+		return fmt.Sprintf("%04d", v)
+	}
+	return fmt.Sprintf("%d", v)
+}
+
+// min returns the lowest valid value of the radio
+func (r *RadioModel) min() int {
+	switch r.kind {
+	case RadioCOM:
+		return 118000
+	case RadioNAV, RadioDME:
+		return 108000
+	case RadioADF:
+		return 190
+	}
+	return 0
+}
+
+// comChannelValid returns true if khz is a valid kHz part of a COM
+// frequency with the radio's channel spacing
+func (r *RadioModel) comChannelValid(khz int) bool {
+	if r.spacing == Spacing8_33kHz {
+		return khz%5 == 0 && khz%25 != 20
+	}
+	return khz%25 == 0
+}
+
+// DisplayRadio displays the active and standby values of the radio r on the
+// displays given by active and standby
+func (panel *RadioPanel) DisplayRadio(r *RadioModel, active, standby DisplayID) error {
+	if err := panel.DisplayGlyphs(active, r.Glyphs(r.active)); err != nil {
+		return err
+	}
+	return panel.DisplayGlyphs(standby, r.Glyphs(r.standby))
+}
+
+// wrap returns i modulo n in the range 0 to n-1
+func wrap(i, n int) int {
+	i %= n
+	if i < 0 {
+		i += n
+	}
+	return i
+}
+
+// adfHundreds steps the hundreds of the ADF frequency khz by n. The lower
+// digits are kept, and the hundreds wrap around within the 190-1750 kHz
+// band, for example from 1690 to 190 for frequencies ending in 90.
+func adfHundreds(khz, n int) int {
+	low := khz % 100
+	first, last := 2, 17
+	if low >= 90 {
+		first = 1
+	}
+	if low > 50 {
+		last = 16
+	}
+	return (wrap(khz/100-first+n, last-first+1)+first)*100 + low
+}
+
+// octalDigits returns true if all decimal digits of v are 0-7
+func octalDigits(v int) bool {
+	for ; v > 0; v /= 10 {
+		if v%10 > 7 {
+			return false
+		}
+	}
+	return true
+}
+
+// octalPair steps the two octal digits written as the decimal number v by
+// n, wrapping around from 77 to 00
+func octalPair(v, n int) int {
+	i := wrap(v/10*8+v%10+n, 64)
+	return i/8*10 + i%8
+}
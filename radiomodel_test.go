@@ -0,0 +1,146 @@
+package fpanels
+
+import "testing"
+
+func TestRadioModelTune(t *testing.T) {
+	tests := []struct {
+		kind    RadioKind
+		spacing ChannelSpacing
+		standby int
+		coarse  int
+		fine    int
+		want    int
+	}{
+		{RadioCOM, Spacing25kHz, 118000, 1, 0, 119000},
+		{RadioCOM, Spacing25kHz, 118250, -1, 0, 136250},
+		{RadioCOM, Spacing25kHz, 136975, 1, 0, 118975},
+		{RadioCOM, Spacing25kHz, 118000, 0, 1, 118025},
+		{RadioCOM, Spacing25kHz, 118975, 0, 1, 118000},
+		{RadioCOM, Spacing25kHz, 118000, 0, -1, 118975},
+		{RadioCOM, Spacing8_33kHz, 118000, 0, 1, 118005},
+		{RadioCOM, Spacing8_33kHz, 118015, 0, 1, 118025},
+		{RadioCOM, Spacing8_33kHz, 118025, 0, -1, 118015},
+		{RadioCOM, Spacing8_33kHz, 118000, 0, -1, 118990},
+		{RadioNAV, Spacing25kHz, 108000, 1, 0, 109000},
+		{RadioNAV, Spacing25kHz, 117950, 1, 0, 108950},
+		{RadioNAV, Spacing25kHz, 108950, 0, 1, 108000},
+		{RadioDME, Spacing25kHz, 108000, 0, -1, 108950},
+		{RadioADF, Spacing25kHz, 190, -1, 0, 1690},
+		{RadioADF, Spacing25kHz, 1690, 1, 0, 190},
+		{RadioADF, Spacing25kHz, 1700, 1, 0, 200},
+		{RadioADF, Spacing25kHz, 1750, 1, 0, 250},
+		{RadioADF, Spacing25kHz, 250, -1, 0, 1750},
+		{RadioADF, Spacing25kHz, 355, 2, 0, 555},
+		{RadioADF, Spacing25kHz, 1651, 1, 0, 251},
+		{RadioADF, Spacing25kHz, 1750, 0, 1, 190},
+		{RadioADF, Spacing25kHz, 190, 0, -1, 1750},
+		{RadioXPDR, Spacing25kHz, 1200, 1, 0, 1300},
+		{RadioXPDR, Spacing25kHz, 7700, 1, 0, 0},
+		{RadioXPDR, Spacing25kHz, 1277, 0, 1, 1200},
+		{RadioXPDR, Spacing25kHz, 1200, 0, -1, 1277},
+		{RadioXPDR, Spacing25kHz, 1207, 0, 1, 1210},
+	}
+	for _, tt := range tests {
+		r := NewRadioModel(tt.kind)
+		r.SetChannelSpacing(tt.spacing)
+		if err := r.SetStandby(tt.standby); err != nil {
+			t.Fatalf("%v SetStandby(%d): %v", tt.kind, tt.standby, err)
+		}
+		if tt.coarse != 0 {
+			r.TuneCoarse(tt.coarse)
+		}
+		if tt.fine != 0 {
+			r.TuneFine(tt.fine)
+		}
+		if got := r.Standby(); got != tt.want {
+			t.Errorf("%v %d tuned %d/%d = %d, want %d", tt.kind, tt.standby, tt.coarse, tt.fine, got, tt.want)
+		}
+		if !r.Valid(r.Standby()) {
+			t.Errorf("%v %d tuned %d/%d gave invalid %d", tt.kind, tt.standby, tt.coarse, tt.fine, r.Standby())
+		}
+	}
+}
+
+func TestRadioModelValid(t *testing.T) {
+	tests := []struct {
+		kind    RadioKind
+		spacing ChannelSpacing
+		v       int
+		want    bool
+	}{
+		{RadioCOM, Spacing25kHz, 118000, true},
+		{RadioCOM, Spacing25kHz, 136975, true},
+		{RadioCOM, Spacing25kHz, 137000, false},
+		{RadioCOM, Spacing25kHz, 117975, false},
+		{RadioCOM, Spacing25kHz, 118005, false},
+		{RadioCOM, Spacing8_33kHz, 118005, true},
+		{RadioCOM, Spacing8_33kHz, 118020, false},
+		{RadioCOM, Spacing8_33kHz, 136990, true},
+		{RadioNAV, Spacing25kHz, 108000, true},
+		{RadioNAV, Spacing25kHz, 108025, false},
+		{RadioNAV, Spacing25kHz, 118000, false},
+		{RadioDME, Spacing25kHz, 117950, true},
+		{RadioADF, Spacing25kHz, 189, false},
+		{RadioADF, Spacing25kHz, 190, true},
+		{RadioADF, Spacing25kHz, 1750, true},
+		{RadioADF, Spacing25kHz, 1751, false},
+		{RadioXPDR, Spacing25kHz, 0, true},
+		{RadioXPDR, Spacing25kHz, 7777, true},
+		{RadioXPDR, Spacing25kHz, 1280, false},
+		{RadioXPDR, Spacing25kHz, 10000, false},
+		{RadioXPDR, Spacing25kHz, -1, false},
+	}
+	for _, tt := range tests {
+		r := NewRadioModel(tt.kind)
+		r.SetChannelSpacing(tt.spacing)
+		if got := r.Valid(tt.v); got != tt.want {
+			t.Errorf("%v Valid(%d) = %v, want %v", tt.kind, tt.v, got, tt.want)
+		}
+		err := r.SetActive(tt.v)
+		if (err == nil) != tt.want {
+			t.Errorf("%v SetActive(%d) = %v", tt.kind, tt.v, err)
+		}
+	}
+}
+
+func TestRadioModelGlyphs(t *testing.T) {
+	tests := []struct {
+		kind RadioKind
+		v    int
+		want string
+		s    string
+	}{
+		{RadioCOM, 118250, "|1|8.|2|5|0|", "118.250"},
+		{RadioCOM, 136005, "|3|6.|0|0|5|", "136.005"},
+		{RadioNAV, 108000, "|1|0|8.|0|0|", "108.00"},
+		{RadioDME, 117950, "|1|1|7.|9|5|", "117.95"},
+		{RadioADF, 1750, "| |1|7|5|0|", "1750"},
+		{RadioADF, 190, "| | |1|9|0|", "190"},
+		{RadioXPDR, 7000, "| |7|0|0|0|", "7000"},
+		{RadioXPDR, 12, "| |0|0|1|2|", "0012"},
+	}
+	for _, tt := range tests {
+		r := NewRadioModel(tt.kind)
+		if s := glyphsString(r.Glyphs(tt.v)); s != tt.want {
+			t.Errorf("%v Glyphs(%d) = %s, want %s", tt.kind, tt.v, s, tt.want)
+		}
+		if s := r.ValueString(tt.v); s != tt.s {
+			t.Errorf("%v ValueString(%d) = %q, want %q", tt.kind, tt.v, s, tt.s)
+		}
+	}
+}
+
+func TestRadioModelChannelSpacing(t *testing.T) {
+	r := NewRadioModel(RadioCOM)
+	r.SetChannelSpacing(Spacing8_33kHz)
+	r.SetActive(118015)
+	r.SetStandby(118030)
+	r.SetChannelSpacing(Spacing25kHz)
+	if r.Active() != 118000 || r.Standby() != 118025 {
+		t.Errorf("Rounded to %d and %d, want 118000 and 118025", r.Active(), r.Standby())
+	}
+	r.Swap()
+	if r.Active() != 118025 || r.Standby() != 118000 {
+		t.Errorf("Swapped to %d and %d, want 118025 and 118000", r.Active(), r.Standby())
+	}
+}
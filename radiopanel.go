@@ -3,6 +3,7 @@ package fpanels
 import (
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/google/gousb"
 )
@@ -63,16 +64,22 @@ type RadioPanel struct {
 }
 
 // NewRadioPanel creats a new instance of the radio panel
-func NewRadioPanel() (*RadioPanel, error) {
+func NewRadioPanel(opts ...Option) (*RadioPanel, error) {
 	var err error
 	panel := RadioPanel{}
 	panel.id = Radio
+	panel.ledIndex = -1
 	panel.displayState = make([]byte, 22)
 	for i := range panel.displayState {
 		panel.displayState[i] = blank
 	}
+	panel.blankFrame = append([]byte(nil), panel.displayState...)
+	for _, opt := range opts {
+		opt(&panel.panel)
+	}
 	panel.displayDirty = true
 	panel.displayCond = sync.NewCond(&panel.displayMutex)
+	panel.epoch = time.Now()
 	panel.ctx = gousb.NewContext()
 	panel.device, err = panel.ctx.OpenDeviceWithVIDPID(USBVendorPanel, USBProductRadio)
 	if panel.device == nil || err != nil {
@@ -95,9 +102,10 @@ func NewRadioPanel() (*RadioPanel, error) {
 	}
 
 	panel.switchCh = make(chan SwitchState)
+	panel.idleCh = make(chan IdleState, 4)
 	go panel.readSwitches()
 
-	// FIX: Add WaitGroup
+	panel.wg.Add(1)
 	go panel.refreshDisplay()
 	panel.connected = true
 	return &panel, nil
@@ -149,20 +157,18 @@ func (panel *RadioPanel) DisplayString(display DisplayID, s string) {
 		}
 	}
 
-	panel.displayMutex.Lock()
-	defer panel.displayMutex.Unlock()
-	panel.displayDirty = true
-	panel.displayCond.Signal()
+	var b [5]byte
 	dIdx--
 	// align right and fill with blanks
 	for i := 4; i >= 0; i-- {
 		if dIdx < 0 {
-			disp[i] = blank
+			b[i] = blank
 		} else {
-			disp[i] = d[dIdx]
+			b[i] = d[dIdx]
 		}
 		dIdx--
 	}
+	panel.setDisplayBytes(displayStart, b[:])
 }
 
 // DisplayInt displays the integer n on the given display
@@ -176,33 +182,137 @@ func (panel *RadioPanel) DisplayFloat(display DisplayID, n float64, decimals int
 	panel.DisplayString(display, fmt.Sprintf("%.*f", decimals, n))
 }
 
-// DisplayOff turns the display off
-func (panel *RadioPanel) DisplayOff() {
-	panel.displayMutex.Lock()
-	for i := 0; i < len(panel.displayState); i++ {
-		panel.displayState[i] = 0xff
+// SetDigit sets the digit at position pos on the given display to the glyph
+// g. Positions are numbered 0-4 from the left. All other digits are left
+// intact. The radio panel displays can show all glyphs.
+func (panel *RadioPanel) SetDigit(display DisplayID, pos int, g Glyph) error {
+	if display < Display1Active || display > Display2Standby {
+		return ErrUnknownDisplay
 	}
-	panel.displayDirty = true
-	panel.displayCond.Signal()
-	panel.displayMutex.Unlock()
+	if pos < 0 || pos > 4 {
+		return ErrInvalidPosition
+	}
+	panel.setDisplayBytes(int(display)*5+pos, []byte{radioGlyphByte(g)})
+	return nil
+}
 
+// Digits returns the five glyphs currently set on the given display, or nil
+// if the display is unknown
+func (panel *RadioPanel) Digits(display DisplayID) []Glyph {
+	if display < Display1Active || display > Display2Standby {
+		return nil
+	}
+	glyphs := make([]Glyph, 5)
+	for i, b := range panel.displayBytes(int(display)*5, 5) {
+		glyphs[i] = radioByteGlyph(b)
+	}
+	return glyphs
 }
-func (panel *RadioPanel) refreshDisplay() {
-	for {
-		panel.displayMutex.Lock()
-		for !panel.displayDirty {
-			panel.displayCond.Wait()
+
+// DisplayGlyphs displays the glyphs g on the given display. At most five
+// glyphs can be given. The glyphs are aligned right and filled with blanks.
+func (panel *RadioPanel) DisplayGlyphs(display DisplayID, g []Glyph) error {
+	b, err := radioGlyphBytes(display, g)
+	if err != nil {
+		return err
+	}
+	panel.setDisplayBytes(int(display)*5, b)
+	return nil
+}
+
+// radioGlyphBytes encodes the glyphs g right aligned as the five display
+// bytes of the given display
+func radioGlyphBytes(display DisplayID, g []Glyph) ([]byte, error) {
+	if display < Display1Active || display > Display2Standby {
+		return nil, ErrUnknownDisplay
+	}
+	if len(g) > 5 {
+		return nil, ErrOverflow
+	}
+	b := make([]byte, 5)
+	for i := range b {
+		b[i] = blank
+	}
+	for i, glyph := range g {
+		b[5-len(g)+i] = radioGlyphByte(glyph)
+	}
+	return b, nil
+}
+
+// DisplayIntFormat displays the integer n on the given display formatted
+// according to f. ErrOverflow is returned if the number does not fit and
+// f.Overflow is OverflowError.
+func (panel *RadioPanel) DisplayIntFormat(display DisplayID, n int, f Format) error {
+	g, err := FormatInt(n, 5, f)
+	if err != nil {
+		return err
+	}
+	return panel.DisplayGlyphs(display, g)
+}
+
+// DisplayFloatFormat displays the floating point number n on the given
+// display with f.Decimals decimals. ErrOverflow is returned if the number
+// does not fit and f.Overflow is OverflowError.
+func (panel *RadioPanel) DisplayFloatFormat(display DisplayID, n float64, f Format) error {
+	g, err := FormatFloat(n, 5, f)
+	if err != nil {
+		return err
+	}
+	return panel.DisplayGlyphs(display, g)
+}
+
+// BlinkDigit makes the digit at position pos on the given display blink with
+// the pattern b. Positions are numbered 0-4 from the left. Use NoBlink to
+// stop blinking.
+func (panel *RadioPanel) BlinkDigit(display DisplayID, pos int, b Blink) error {
+	if display < Display1Active || display > Display2Standby {
+		return ErrUnknownDisplay
+	}
+	if pos < 0 || pos > 4 {
+		return ErrInvalidPosition
+	}
+	panel.setBlink(int(display)*5+pos, 0xff, b)
+	return nil
+}
+
+// BlinkDisplay makes all digits on the given display blink with the pattern
+// b. Use NoBlink to stop blinking.
+func (panel *RadioPanel) BlinkDisplay(display DisplayID, b Blink) error {
+	for pos := 0; pos < 5; pos++ {
+		if err := panel.BlinkDigit(display, pos, b); err != nil {
+			return err
 		}
-		// 0x09 is REQUEST_SET_CONFIGURATION
-		// 0x0300 is:
-		// 	 0x03 HID_REPORT_TYPE_FEATURE
-		//   0x00 Report ID 0
-		panel.device.Control(gousb.ControlOut|gousb.ControlClass|gousb.ControlInterface, 0x09,
-			0x0300, 0x00, panel.displayState[:])
-		// FIX: Check if Control() returns an error and return it somehow or exit
-		panel.displayDirty = false
-		panel.displayMutex.Unlock()
 	}
+	return nil
+}
+
+// LayerGlyphs shows the glyphs g on the given display in the display layer
+// with the given name. The glyphs are aligned right and filled with blanks.
+// The layer is created if it does not exist. A layer covers the displays it
+// has been given glyphs for, and the layer with the highest priority is
+// shown. The layer is removed after timeout, which is restarted on every
+// update. A zero timeout keeps the layer until RemoveLayer is called. The
+// displays set by the Display* functions are below all layers. For
+// example, to show a warning on the active displays for three seconds:
+//   g, _ := FormatInt(7700, 5, Format{})
+//   panel.LayerGlyphs("warning", 10, 3*time.Second, Display1Active, g)
+//   panel.LayerGlyphs("warning", 10, 3*time.Second, Display2Active, g)
+func (panel *RadioPanel) LayerGlyphs(name string, priority int, timeout time.Duration, display DisplayID, g []Glyph) error {
+	b, err := radioGlyphBytes(display, g)
+	if err != nil {
+		return err
+	}
+	panel.setLayer(name, priority, timeout, int(display)*5, b, []byte{0xff, 0xff, 0xff, 0xff, 0xff})
+	return nil
+}
+
+// DisplayOff turns the display off
+func (panel *RadioPanel) DisplayOff() {
+	b := make([]byte, len(panel.displayState))
+	for i := range b {
+		b[i] = 0xff
+	}
+	panel.setDisplayBytes(0, b)
 }
 
 func (panel *RadioPanel) noZeroSwitch(s SwitchID) bool {
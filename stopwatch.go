@@ -0,0 +1,181 @@
+package fpanels
+
+import (
+	"sync"
+	"time"
+)
+
+// Stopwatch is a RadioWidget that shows the elapsed time on the active
+// display as minutes and seconds, for example "  3.05" for three minutes
+// and five seconds. Releasing the ACT/STBY button starts and stops the
+// stopwatch. Holding the button for the long press time stops and resets
+// it. The standby display is blank. A Stopwatch is safe for concurrent use.
+//
+// Use it to time approaches and holds in a spare selector position:
+//   c := fpanels.NewRadioController(panel)
+//   c.SetWidget(fpanels.PosADF, fpanels.NewStopwatch(c.RadioMemory))
+type Stopwatch struct {
+	memory    *RadioMemory
+	mutex     sync.Mutex
+	started   time.Time
+	elapsed   time.Duration
+	pressed   time.Time
+	longPress time.Duration
+	timer     *time.Timer
+	// gen identifies the current timer, so that a tick of a stopped timer
+	// that has already fired is ignored
+	gen int
+}
+
+// NewStopwatch creates a new stopped stopwatch. The radio memory is
+// repainted every second while the stopwatch is running.
+func NewStopwatch(m *RadioMemory) *Stopwatch {
+	return &Stopwatch{memory: m, longPress: DefaultLongPress}
+}
+
+// SetLongPress sets the time the ACT/STBY button must be held to reset the
+// stopwatch
+func (w *Stopwatch) SetLongPress(d time.Duration) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	w.longPress = d
+}
+
+// Elapsed returns the elapsed time
+func (w *Stopwatch) Elapsed() time.Duration {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	return w.elapsedAt(time.Now())
+}
+
+// Running returns true if the stopwatch is running
+func (w *Stopwatch) Running() bool {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	return !w.started.IsZero()
+}
+
+// Start starts the stopwatch if it is stopped
+func (w *Stopwatch) Start() {
+	w.mutex.Lock()
+	w.start(time.Now())
+	w.mutex.Unlock()
+	w.memory.Repaint()
+}
+
+// Stop stops the stopwatch if it is running
+func (w *Stopwatch) Stop() {
+	w.mutex.Lock()
+	w.stop(time.Now())
+	w.mutex.Unlock()
+	w.memory.Repaint()
+}
+
+// Reset stops the stopwatch and sets the elapsed time to zero
+func (w *Stopwatch) Reset() {
+	w.mutex.Lock()
+	w.stop(time.Now())
+	w.elapsed = 0
+	w.mutex.Unlock()
+	w.memory.Repaint()
+}
+
+// Input handles an input at the time now. It returns true if the stopwatch
+// was started, stopped or reset.
+func (w *Stopwatch) Input(in RadioInput, now time.Time) bool {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	switch in {
+	case RadioActPressed:
+		w.pressed = now
+	case RadioActReleased:
+		if w.pressed.IsZero() {
+			return false
+		}
+		long := now.Sub(w.pressed) >= w.longPress
+		w.pressed = time.Time{}
+		switch {
+		case long:
+			w.stop(now)
+			w.elapsed = 0
+		case w.started.IsZero():
+			w.start(now)
+		default:
+			w.stop(now)
+		}
+		return true
+	}
+	return false
+}
+
+// Glyphs returns the glyphs of the active and standby displays
+func (w *Stopwatch) Glyphs() (active, standby []Glyph) {
+	w.mutex.Lock()
+	s := int(w.elapsedAt(time.Now()) / time.Second)
+	w.mutex.Unlock()
+	if s > 999*60+59 {
+		s = 999*60 + 59
+	}
+	active, _ = FormatInt(s/60, 3, Format{})
+	active[2] = active[2].WithDot()
+	seconds, _ := FormatInt(s%60, 2, Format{ZeroPad: true})
+	active = append(active, seconds...)
+	standby = []Glyph{GlyphBlank, GlyphBlank, GlyphBlank, GlyphBlank, GlyphBlank}
+	return active, standby
+}
+
+// elapsedAt returns the elapsed time at the time now. The stopwatch mutex
+// must be held.
+func (w *Stopwatch) elapsedAt(now time.Time) time.Duration {
+	if w.started.IsZero() {
+		return w.elapsed
+	}
+	return w.elapsed + now.Sub(w.started)
+}
+
+// start starts the stopwatch at the time now. The stopwatch mutex must be
+// held.
+func (w *Stopwatch) start(now time.Time) {
+	if !w.started.IsZero() {
+		return
+	}
+	w.started = now
+	w.scheduleTick(now)
+}
+
+// stop stops the stopwatch at the time now. The stopwatch mutex must be
+// held.
+func (w *Stopwatch) stop(now time.Time) {
+	if w.started.IsZero() {
+		return
+	}
+	w.elapsed += now.Sub(w.started)
+	w.started = time.Time{}
+	w.gen++
+	if w.timer != nil {
+		w.timer.Stop()
+		w.timer = nil
+	}
+}
+
+// scheduleTick repaints the radio memory when the next whole second has
+// elapsed. The stopwatch mutex must be held.
+func (w *Stopwatch) scheduleTick(now time.Time) {
+	d := time.Second - w.elapsedAt(now)%time.Second
+	w.gen++
+	gen := w.gen
+	w.timer = time.AfterFunc(d, func() { w.tick(gen) })
+}
+
+// tick repaints the radio memory and schedules the next tick while the
+// stopwatch is running. gen is the generation of the timer that fired.
+func (w *Stopwatch) tick(gen int) {
+	w.mutex.Lock()
+	if gen != w.gen || w.started.IsZero() {
+		w.mutex.Unlock()
+		return
+	}
+	w.scheduleTick(time.Now())
+	w.mutex.Unlock()
+	w.memory.Repaint()
+}
@@ -0,0 +1,100 @@
+package fpanels
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStopwatchInput(t *testing.T) {
+	start := time.Now()
+	w := NewStopwatch(NewRadioMemory(newTestRadioPanel()))
+	defer w.Stop()
+	steps := []struct {
+		in      RadioInput
+		at      time.Duration
+		changed bool
+		running bool
+		elapsed time.Duration
+	}{
+		{RadioActReleased, 0, false, false, 0},
+		{RadioActPressed, 0, false, false, 0},
+		{RadioActReleased, 0, true, true, 0},
+		{RadioActPressed, 65 * time.Second, false, true, 65 * time.Second},
+		{RadioActReleased, 65 * time.Second, true, false, 65 * time.Second},
+		{RadioActPressed, 70 * time.Second, false, false, 65 * time.Second},
+		{RadioActReleased, 70 * time.Second, true, true, 65 * time.Second},
+		{RadioActPressed, 80 * time.Second, false, true, 75 * time.Second},
+		{RadioActReleased, 82 * time.Second, true, false, 0},
+	}
+	for i, s := range steps {
+		now := start.Add(s.at)
+		changed := w.Input(s.in, now)
+		w.mutex.Lock()
+		elapsed := w.elapsedAt(now)
+		w.mutex.Unlock()
+		if changed != s.changed || w.Running() != s.running || elapsed != s.elapsed {
+			t.Errorf("Step %d: %v, %v, %v, want %v, %v, %v", i, changed, w.Running(), elapsed, s.changed, s.running, s.elapsed)
+		}
+	}
+}
+
+func TestStopwatchGlyphs(t *testing.T) {
+	tests := []struct {
+		elapsed time.Duration
+		want    string
+	}{
+		{0, "| | |0.|0|0|"},
+		{65 * time.Second, "| | |1.|0|5|"},
+		{125*time.Minute + 9*time.Second, "|1|2|5.|0|9|"},
+		{1000 * time.Minute, "|9|9|9.|5|9|"},
+	}
+	for _, tt := range tests {
+		w := &Stopwatch{elapsed: tt.elapsed}
+		active, standby := w.Glyphs()
+		if s := glyphsString(active); s != tt.want || glyphsString(standby) != "| | | | | |" {
+			t.Errorf("Glyphs at %v = %s %s, want %s", tt.elapsed, s, glyphsString(standby), tt.want)
+		}
+	}
+}
+
+func TestStopwatchStartStop(t *testing.T) {
+	w := NewStopwatch(NewRadioMemory(newTestRadioPanel()))
+	running := func() bool {
+		w.mutex.Lock()
+		defer w.mutex.Unlock()
+		return !w.started.IsZero() && w.timer != nil
+	}
+	w.Start()
+	if !running() {
+		t.Fatal("Start did not start the stopwatch")
+	}
+	w.Stop()
+	if running() || w.Running() {
+		t.Error("Stop did not stop the stopwatch")
+	}
+	w.Reset()
+	if w.Elapsed() != 0 {
+		t.Errorf("Elapsed after Reset = %v, want 0", w.Elapsed())
+	}
+}
+
+func TestStopwatchStaleTick(t *testing.T) {
+	w := NewStopwatch(NewRadioMemory(newTestRadioPanel()))
+	w.Start()
+	w.mutex.Lock()
+	stale := w.gen
+	w.mutex.Unlock()
+	w.Stop()
+	w.Start()
+	defer w.Stop()
+	w.mutex.Lock()
+	timer, gen := w.timer, w.gen
+	w.mutex.Unlock()
+	// The tick of the first timer runs after the restart
+	w.tick(stale)
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	if w.timer != timer || w.gen != gen {
+		t.Error("Stale tick rescheduled the running stopwatch")
+	}
+}
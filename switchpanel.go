@@ -2,6 +2,7 @@ package fpanels
 
 import (
 	"sync"
+	"time"
 
 	"github.com/google/gousb"
 )
@@ -64,14 +65,20 @@ type SwitchPanel struct {
 }
 
 // NewSwitchPanel create a new instance of the Logitech/Saitek switch panel
-func NewSwitchPanel() (*SwitchPanel, error) {
+func NewSwitchPanel(opts ...Option) (*SwitchPanel, error) {
 	var err error
 	panel := SwitchPanel{}
 	panel.id = Switch
+	panel.ledIndex = 0
 	panel.displayState = make([]byte, 1)
 	panel.displayState[0] = 0
+	panel.blankFrame = append([]byte(nil), panel.displayState...)
+	for _, opt := range opts {
+		opt(&panel.panel)
+	}
 	panel.displayDirty = true
 	panel.displayCond = sync.NewCond(&panel.displayMutex)
+	panel.epoch = time.Now()
 	panel.ctx = gousb.NewContext()
 	panel.device, err = panel.ctx.OpenDeviceWithVIDPID(USBVendorPanel, USBProductSwitch)
 	if panel.device == nil || err != nil {
@@ -91,9 +98,10 @@ func NewSwitchPanel() (*SwitchPanel, error) {
 		panel.Close()
 		return nil, err
 	}
-	// FIX: Add WaitGroup
 	panel.switchCh = make(chan SwitchState)
+	panel.idleCh = make(chan IdleState, 4)
 	go panel.readSwitches()
+	panel.wg.Add(1)
 	go panel.refreshDisplay()
 	panel.connected = true
 	return &panel, nil
@@ -105,11 +113,7 @@ func NewSwitchPanel() (*SwitchPanel, error) {
 // will turn on the left and right green landing gear LEDs and turn off
 // all other LEDs
 func (panel *SwitchPanel) LEDs(leds byte) {
-	panel.displayMutex.Lock()
-	panel.displayState[0] = leds
-	panel.displayDirty = true
-	panel.displayCond.Signal()
-	panel.displayMutex.Unlock()
+	panel.setLEDs(leds, 0xff)
 }
 
 // LEDsOn turns on the ELDS given by leds and leaves the other LED states
@@ -117,11 +121,7 @@ func (panel *SwitchPanel) LEDs(leds byte) {
 // together. For example:
 //  panel.LEDsOn(LEDLGreen | LEDRGreen)
 func (panel *SwitchPanel) LEDsOn(leds byte) {
-	panel.displayMutex.Lock()
-	panel.displayState[0] = panel.displayState[0] | leds
-	panel.displayDirty = true
-	panel.displayCond.Signal()
-	panel.displayMutex.Unlock()
+	panel.setLEDs(leds, leds)
 }
 
 // LEDsOff turn off the LEDs given by leds and leaves all other LED states
@@ -129,24 +129,49 @@ func (panel *SwitchPanel) LEDsOn(leds byte) {
 // together. For example:
 //   panel.LEDsOff(LEDLGreen | LEDRGreen)
 func (panel *SwitchPanel) LEDsOff(leds byte) {
-	panel.displayMutex.Lock()
-	panel.displayState[0] = panel.displayState[0] & ^leds
-	panel.displayDirty = true
-	panel.displayCond.Signal()
-	panel.displayMutex.Unlock()
+	panel.setLEDs(0, leds)
 }
 
 // LEDsOnOff turns on or off the LEDs given by leds. If val is 0 then
-// the LEDs will be turned off, else they will be turned on. All other
-// LEDs are left intact. See the switch panel LED constants.
-// Multiple LEDs can be ORed togethe, for example:
+// the LEDs will be turned off, else they will be turned on. If the panel
+// was created with the DimmableLEDs option, then a val between 0 and 1 sets
+// the brightness of the LEDs. All other LEDs are left intact. See the
+// switch panel LED constants. Multiple LEDs can be ORed togethe, for
+// example:
 //   panel.LEDsOnOff(LEDLGreen | LEDRGreen, 1)
 func (panel *SwitchPanel) LEDsOnOff(leds byte, val float64) {
-	if val > 0 {
-		panel.LEDsOn(leds)
-	} else {
-		panel.LEDsOff(leds)
-	}
+	panel.setLEDLevel(leds, val)
+}
+
+// BlinkLEDs makes the LEDs given by leds blink with the pattern b. The LEDs
+// only blink while they are turned on. Use NoBlink to stop blinking. For
+// example, to flash all red LEDs:
+//   panel.LEDsOn(LEDAllRed)
+//   panel.BlinkLEDs(LEDAllRed, DefaultBlink)
+func (panel *SwitchPanel) BlinkLEDs(leds byte, b Blink) {
+	panel.setBlink(0, leds, b)
+}
+
+// Animate starts the LED animation a and returns its ID. See Animation. For
+// example
+//   a := Alternate(500*time.Millisecond, LEDAllRed, LEDAllGreen)
+//   a.Repeat = 4
+//   panel.Animate(a)
+func (panel *SwitchPanel) Animate(a Animation) AnimationID {
+	return panel.animate(0, a)
+}
+
+// StopAnimation stops the LED animation with the given ID. The LEDs return
+// to their steady state.
+func (panel *SwitchPanel) StopAnimation(id AnimationID) {
+	panel.stopAnimation(id)
+}
+
+// LayerLEDs sets the LEDs given by mask to the state given by leds in the
+// display layer with the given name. See RadioPanel.LayerGlyphs. LEDs not
+// in mask are not covered by the layer.
+func (panel *SwitchPanel) LayerLEDs(name string, priority int, timeout time.Duration, leds byte, mask byte) {
+	panel.setLayer(name, priority, timeout, 0, []byte{leds}, []byte{mask})
 }
 
 func (panel *SwitchPanel) noZeroSwitch(s SwitchID) bool {
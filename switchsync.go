@@ -0,0 +1,166 @@
+package fpanels
+
+import (
+	"sync"
+	"time"
+)
+
+// SyncPolicy decides how a SwitchSync resolves mismatches
+type SyncPolicy int
+
+// Sync policies
+const (
+	// SyncPushPhysical reports the physical switch states so that the
+	// application can set them in the simulator. The physical states then
+	// become the desired states.
+	SyncPushPhysical SyncPolicy = iota
+	// SyncFlashGear flashes the gear LEDs until the switches have been
+	// moved to the desired states by hand
+	SyncFlashGear
+)
+
+// syncFlash is the time the gear LEDs are on and off when flashing
+const syncFlash = 250 * time.Millisecond
+
+// SyncEvent is sent when the mismatched switches have changed
+type SyncEvent struct {
+	// Mismatches are the switches that are not in the desired state
+	Mismatches []SwitchID
+	// Physical is the state of the switches on the panel
+	Physical PanelSwitches
+	// Pushed is true if the physical states of the mismatched switches
+	// should be set in the simulator, see SyncPushPhysical
+	Pushed bool
+}
+
+// SwitchSync compares the switches of the switch panel with the desired
+// states, for example the switch states of a scenario loaded in a
+// simulator. Set the desired states with SetDesired. A SyncEvent is sent
+// when the mismatches have been resolved by the policy, and, with the
+// SyncFlashGear policy, every time the mismatches change.
+//
+// The gear LEDs are flashed with an Animation that covers the gear
+// indicator until the switches are in sync.
+type SwitchSync struct {
+	panel    *SwitchPanel
+	mutex    sync.Mutex
+	desired  PanelSwitches
+	mask     PanelSwitches
+	policy   SyncPolicy
+	last     PanelSwitches
+	flash    AnimationID
+	flashing bool
+	eventCh  chan SyncEvent
+}
+
+// NewSwitchSync creates a new switch sync for the switch panel with the
+// given policy, and starts handling its switch events
+func NewSwitchSync(panel *SwitchPanel, policy SyncPolicy) *SwitchSync {
+	s := &SwitchSync{
+		panel:   panel,
+		policy:  policy,
+		eventCh: make(chan SyncEvent, 16),
+	}
+	panel.AddSwitchHandler(s)
+	return s
+}
+
+// EventCh returns a channel for sync events
+func (s *SwitchSync) EventCh() chan SyncEvent {
+	return s.eventCh
+}
+
+// SetPolicy sets the policy used for the next mismatches
+func (s *SwitchSync) SetPolicy(p SyncPolicy) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.policy = p
+}
+
+// SetDesired sets the desired states of the switches given by mask and
+// resolves any mismatches with the policy. Switches outside mask are not
+// compared. For example, to require the battery on and the avionics off:
+//   s.SetDesired(1<<fpanels.SwBat, 1<<fpanels.SwBat|1<<fpanels.SwAvionics)
+func (s *SwitchSync) SetDesired(desired PanelSwitches, mask PanelSwitches) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.desired = desired
+	s.mask = mask
+	s.last = 0
+	s.update(true)
+}
+
+// Mismatches returns the switches that are not in the desired state
+func (s *SwitchSync) Mismatches() []SwitchID {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return mismatches(s.desired, s.mask, s.panel.Switches()).list()
+}
+
+// HandleSwitch handles the switch panel switch event s. It is called by the
+// panel, see AddSwitchHandler.
+func (s *SwitchSync) HandleSwitch(state SwitchState) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.update(false)
+}
+
+// update compares the switches with the desired states and resolves the
+// mismatches. Mismatches are resolved after SetDesired, when force is true,
+// and as switches are moved. The sync mutex must be held.
+func (s *SwitchSync) update(force bool) {
+	physical := s.panel.Switches()
+	m := mismatches(s.desired, s.mask, physical)
+	if !force && m == s.last {
+		return
+	}
+	s.last = m
+	e := SyncEvent{m.list(), physical, false}
+	switch s.policy {
+	case SyncPushPhysical:
+		s.setFlashing(false)
+		if m == 0 {
+			return
+		}
+		e.Pushed = true
+		s.desired = s.desired&^s.mask | physical&s.mask
+		s.last = 0
+	default:
+		s.setFlashing(m != 0)
+	}
+	select {
+	case s.eventCh <- e:
+	default:
+	}
+}
+
+// setFlashing starts or stops flashing the gear LEDs. The sync mutex must
+// be held.
+func (s *SwitchSync) setFlashing(on bool) {
+	if on == s.flashing {
+		return
+	}
+	s.flashing = on
+	if !on {
+		s.panel.StopAnimation(s.flash)
+		return
+	}
+	s.flash = s.panel.Animate(Alternate(syncFlash, LEDAllYellow, 0))
+}
+
+// mismatches returns the switches in mask where physical differs from
+// desired
+func mismatches(desired, mask, physical PanelSwitches) PanelSwitches {
+	return (desired ^ physical) & mask
+}
+
+// list returns the IDs of the switches that are set
+func (switches PanelSwitches) list() []SwitchID {
+	var ids []SwitchID
+	for id := SwitchID(0); id < 24; id++ {
+		if switches.IsSet(id) {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
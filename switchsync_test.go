@@ -0,0 +1,96 @@
+package fpanels
+
+import (
+	"reflect"
+	"testing"
+)
+
+// nextSyncEvent returns the next sync event, if there is one
+func nextSyncEvent(s *SwitchSync) (SyncEvent, bool) {
+	select {
+	case e := <-s.EventCh():
+		return e, true
+	default:
+		return SyncEvent{}, false
+	}
+}
+
+func TestSyncPushPhysical(t *testing.T) {
+	panel := newTestSwitchPanel()
+	panel.setSwitch(SwBat, true)
+	s := NewSwitchSync(panel, SyncPushPhysical)
+
+	s.SetDesired(1<<SwAvionics, 1<<SwBat|1<<SwAvionics)
+	e, ok := nextSyncEvent(s)
+	want := SyncEvent{[]SwitchID{SwBat, SwAvionics}, 1 << SwBat, true}
+	if !ok || !reflect.DeepEqual(e, want) {
+		t.Fatalf("SetDesired event = %+v, %v, want %+v", e, ok, want)
+	}
+	if m := s.Mismatches(); m != nil {
+		t.Errorf("Mismatches after push = %v, want none", m)
+	}
+	if len(panel.animations) != 0 {
+		t.Errorf("Gear LEDs flashing with SyncPushPhysical")
+	}
+
+	panel.setSwitch(SwNav, true)
+	if e, ok := nextSyncEvent(s); ok {
+		t.Errorf("Event %+v for switch outside mask", e)
+	}
+	panel.setSwitch(SwBat, false)
+	e, ok = nextSyncEvent(s)
+	want = SyncEvent{[]SwitchID{SwBat}, 1 << SwNav, true}
+	if !ok || !reflect.DeepEqual(e, want) {
+		t.Errorf("Switch event = %+v, %v, want %+v", e, ok, want)
+	}
+
+	s.SetDesired(0, 1<<SwBat)
+	if e, ok := nextSyncEvent(s); ok {
+		t.Errorf("Event %+v when already in sync", e)
+	}
+}
+
+func TestSyncFlashGear(t *testing.T) {
+	panel := newTestSwitchPanel()
+	s := NewSwitchSync(panel, SyncFlashGear)
+
+	s.SetDesired(1<<SwBat|1<<SwBeacon, 1<<SwBat|1<<SwBeacon)
+	e, ok := nextSyncEvent(s)
+	want := SyncEvent{[]SwitchID{SwBat, SwBeacon}, 0, false}
+	if !ok || !reflect.DeepEqual(e, want) {
+		t.Fatalf("SetDesired event = %+v, %v, want %+v", e, ok, want)
+	}
+	if f, _ := panel.render(0); f[0] != LEDAllYellow {
+		t.Errorf("Flashing gear LEDs = %#x, want %#x", f[0], LEDAllYellow)
+	}
+	if f, _ := panel.render(syncFlash * 3 / 2); f[0] != 0 {
+		t.Errorf("Flashing gear LEDs off phase = %#x, want 0", f[0])
+	}
+
+	panel.setSwitch(SwBat, true)
+	e, ok = nextSyncEvent(s)
+	want = SyncEvent{[]SwitchID{SwBeacon}, 1 << SwBat, false}
+	if !ok || !reflect.DeepEqual(e, want) {
+		t.Errorf("Switch event = %+v, %v, want %+v", e, ok, want)
+	}
+	panel.setSwitch(SwNav, true)
+	if e, ok := nextSyncEvent(s); ok {
+		t.Errorf("Event %+v for switch outside mask", e)
+	}
+	if len(panel.animations) != 1 {
+		t.Errorf("Gear LEDs not flashing with a mismatch")
+	}
+
+	panel.setSwitch(SwBeacon, true)
+	e, ok = nextSyncEvent(s)
+	want = SyncEvent{nil, 1<<SwBat | 1<<SwBeacon | 1<<SwNav, false}
+	if !ok || !reflect.DeepEqual(e, want) {
+		t.Errorf("In sync event = %+v, %v, want %+v", e, ok, want)
+	}
+	if len(panel.animations) != 0 {
+		t.Errorf("Gear LEDs flashing after sync")
+	}
+	if m := s.Mismatches(); m != nil {
+		t.Errorf("Mismatches = %v, want none", m)
+	}
+}
@@ -0,0 +1,149 @@
+package fpanels
+
+import (
+	"fmt"
+	"time"
+)
+
+// TransponderMode is the operating mode of a transponder
+type TransponderMode int
+
+// Transponder modes
+const (
+	XPDRStandby TransponderMode = iota
+	XPDROn
+	XPDRAlt
+)
+
+// DefaultLongPress is the time the ACT/STBY button must be held for a long
+// press
+const DefaultLongPress = time.Second
+
+// String returns the name of the transponder mode, for example "STBY"
+func (m TransponderMode) String() string {
+	switch m {
+	case XPDRStandby:
+		return "STBY"
+	case XPDROn:
+		return "ON"
+	case XPDRAlt:
+		return "ALT"
+	}
+	return fmt.Sprintf("TransponderMode(%d)", int(m))
+}
+
+// TransponderEditor enters transponder codes one octal digit at a time,
+// like the knob of a real transponder. The active display shows the code
+// that is squawked, which is the active value of the radio. The standby
+// display shows the mode followed by the code being entered, which is the
+// standby value of the radio. Since the displays can not show letters, the
+// mode is shown as 0 for STBY, 1 for ON and 2 for ALT. The dot shows the
+// cursor.
+//
+// The outer knob moves the cursor between the mode and the four digits, and
+// the inner knob changes the mode or the digit under the cursor. Releasing
+// the ACT/STBY button squawks the entered code. Holding the button for the
+// long press time squawks the VFR code, 1200 by default.
+//
+// A TransponderEditor is not safe for concurrent use. The RadioController
+// shows a TransponderEditor in the XPDR position.
+type TransponderEditor struct {
+	radio     *RadioModel
+	mode      TransponderMode
+	cursor    int
+	pressed   time.Time
+	vfrCode   int
+	longPress time.Duration
+}
+
+// NewTransponderEditor creates a new transponder editor for the XPDR radio
+// r. The mode is XPDRStandby and the cursor is on the first digit.
+func NewTransponderEditor(r *RadioModel) *TransponderEditor {
+	return &TransponderEditor{
+		radio:     r,
+		cursor:    1,
+		vfrCode:   1200,
+		longPress: DefaultLongPress,
+	}
+}
+
+// Mode returns the transponder mode
+func (e *TransponderEditor) Mode() TransponderMode {
+	return e.mode
+}
+
+// SetMode sets the transponder mode
+func (e *TransponderEditor) SetMode(m TransponderMode) {
+	if m >= XPDRStandby && m <= XPDRAlt {
+		e.mode = m
+	}
+}
+
+// SetVFRCode sets the code squawked on a long press, for example 1200 or
+// 7000. ErrInvalidValue is returned if code is not a valid transponder
+// code.
+func (e *TransponderEditor) SetVFRCode(code int) error {
+	if !e.radio.Valid(code) {
+		return ErrInvalidValue
+	}
+	e.vfrCode = code
+	return nil
+}
+
+// SetLongPress sets the time the ACT/STBY button must be held for a long
+// press
+func (e *TransponderEditor) SetLongPress(d time.Duration) {
+	e.longPress = d
+}
+
+// Input handles an input at the time now. It returns true if the mode or
+// the radio was changed.
+func (e *TransponderEditor) Input(in RadioInput, now time.Time) bool {
+	switch in {
+	case RadioOuterCW:
+		e.cursor = wrap(e.cursor+1, 5)
+	case RadioOuterCCW:
+		e.cursor = wrap(e.cursor-1, 5)
+	case RadioInnerCW:
+		e.step(1)
+	case RadioInnerCCW:
+		e.step(-1)
+	case RadioActPressed:
+		e.pressed = now
+		return false
+	case RadioActReleased:
+		if e.pressed.IsZero() {
+			return false
+		}
+		if now.Sub(e.pressed) >= e.longPress {
+			e.radio.SetStandby(e.vfrCode)
+		}
+		e.pressed = time.Time{}
+		e.radio.SetActive(e.radio.Standby())
+	}
+	return true
+}
+
+// Glyphs returns the glyphs of the active and standby displays
+func (e *TransponderEditor) Glyphs() (active, standby []Glyph) {
+	active = e.radio.Glyphs(e.radio.Active())
+	standby = e.radio.Glyphs(e.radio.Standby())
+	standby[0] = DigitGlyph(int(e.mode))
+	standby[e.cursor] = standby[e.cursor].WithDot()
+	return active, standby
+}
+
+// step changes the mode or the digit under the cursor n steps
+func (e *TransponderEditor) step(n int) {
+	if e.cursor == 0 {
+		e.mode = TransponderMode(wrap(int(e.mode)+n, 3))
+		return
+	}
+	code := e.radio.Standby()
+	unit := 1
+	for i := e.cursor; i < 4; i++ {
+		unit *= 10
+	}
+	digit := code / unit % 10
+	e.radio.SetStandby(code + (wrap(digit+n, 8)-digit)*unit)
+}
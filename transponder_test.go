@@ -0,0 +1,94 @@
+package fpanels
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTransponderEditor(t *testing.T) {
+	ms := time.Millisecond
+	start := time.Now()
+	e := NewTransponderEditor(NewRadioModel(RadioXPDR))
+	steps := []struct {
+		in      RadioInput
+		at      time.Duration
+		changed bool
+		active  int
+		standby string
+		mode    TransponderMode
+	}{
+		{RadioInnerCW, 0, true, 0, "|0|1.|0|0|0|", XPDRStandby},
+		{RadioOuterCW, 0, true, 0, "|0|1|0.|0|0|", XPDRStandby},
+		{RadioInnerCCW, 0, true, 0, "|0|1|7.|0|0|", XPDRStandby},
+		{RadioOuterCCW, 0, true, 0, "|0|1.|7|0|0|", XPDRStandby},
+		{RadioOuterCCW, 0, true, 0, "|0.|1|7|0|0|", XPDRStandby},
+		{RadioInnerCCW, 0, true, 0, "|2.|1|7|0|0|", XPDRAlt},
+		{RadioOuterCCW, 0, true, 0, "|2|1|7|0|0.|", XPDRAlt},
+		{RadioInnerCW, 0, true, 0, "|2|1|7|0|1.|", XPDRAlt},
+		{RadioActReleased, 0, false, 0, "|2|1|7|0|1.|", XPDRAlt},
+		{RadioActPressed, 0, false, 0, "|2|1|7|0|1.|", XPDRAlt},
+		{RadioActReleased, 100 * ms, true, 1701, "|2|1|7|0|1.|", XPDRAlt},
+		{RadioActPressed, 200 * ms, false, 1701, "|2|1|7|0|1.|", XPDRAlt},
+		{RadioActReleased, 1200 * ms, true, 1200, "|2|1|2|0|0.|", XPDRAlt},
+	}
+	for i, s := range steps {
+		changed := e.Input(s.in, start.Add(s.at))
+		_, standby := e.Glyphs()
+		if changed != s.changed || e.radio.Active() != s.active || glyphsString(standby) != s.standby || e.Mode() != s.mode {
+			t.Errorf("Step %d: %v, %d, %s, %v, want %v, %d, %s, %v", i, changed, e.radio.Active(), glyphsString(standby), e.Mode(), s.changed, s.active, s.standby, s.mode)
+		}
+	}
+}
+
+func TestTransponderVFRCode(t *testing.T) {
+	start := time.Now()
+	e := NewTransponderEditor(NewRadioModel(RadioXPDR))
+	if err := e.SetVFRCode(7000); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.SetVFRCode(7080); err != ErrInvalidValue {
+		t.Errorf("SetVFRCode(7080) = %v, want %v", err, ErrInvalidValue)
+	}
+	e.SetLongPress(2 * time.Second)
+	e.Input(RadioActPressed, start)
+	e.Input(RadioActReleased, start.Add(1500*time.Millisecond))
+	if e.radio.Active() != 0 {
+		t.Errorf("Short press squawked %04d, want 0000", e.radio.Active())
+	}
+	e.Input(RadioActPressed, start)
+	e.Input(RadioActReleased, start.Add(2*time.Second))
+	if e.radio.Active() != 7000 {
+		t.Errorf("Long press squawked %04d, want 7000", e.radio.Active())
+	}
+}
+
+func TestRadioControllerTransponder(t *testing.T) {
+	panel := newTestRadioPanel()
+	c := NewRadioController(panel)
+	c.SetTransponderMode(XPDROn)
+	panel.setSwitch(Rot1XPDR, true)
+	if g := glyphsString(panel.Digits(Display1Standby)); g != "|1|0.|0|0|0|" {
+		t.Errorf("Standby display = %s, want |1|0.|0|0|0|", g)
+	}
+	steps := []struct {
+		id    SwitchID
+		on    bool
+		event bool
+		want  RadioEvent
+	}{
+		{Enc2CW1, true, false, RadioEvent{}},
+		{Enc1CW1, true, true, RadioEvent{PosXPDR, RadioXPDR, 0, 100, false, XPDROn, false, nil}},
+		{SwAct1, true, false, RadioEvent{}},
+		{SwAct1, false, true, RadioEvent{PosXPDR, RadioXPDR, 100, 100, true, XPDROn, false, nil}},
+	}
+	for _, s := range steps {
+		panel.setSwitch(s.id, s.on)
+		e, ok := radioEvent(c)
+		if ok != s.event || e != s.want {
+			t.Errorf("Switch %d %v: event %+v, %v, want %+v, %v", s.id, s.on, e, ok, s.want, s.event)
+		}
+	}
+	if c.TransponderMode() != XPDROn {
+		t.Errorf("Mode = %v, want %v", c.TransponderMode(), XPDROn)
+	}
+}
@@ -0,0 +1,185 @@
+package fpanels
+
+import (
+	"sync"
+	"time"
+)
+
+// AxisSink receives the value of a virtual axis, for example a virtual
+// joystick axis or a simulator variable. The value is between -1 and 1.
+type AxisSink interface {
+	SetAxis(value float64)
+}
+
+// AxisFunc is a function that can be used as an AxisSink. For example
+//   trim.AddSink(fpanels.AxisFunc(func(v float64) {
+//     sim.SetElevatorTrim(v)
+//   }))
+type AxisFunc func(value float64)
+
+// SetAxis calls f(value)
+func (f AxisFunc) SetAxis(value float64) {
+	f(value)
+}
+
+// TrimEvent is sent when the trim value has changed
+type TrimEvent struct {
+	Value float64
+}
+
+// trimStreakInterval is the longest time between two trim wheel clicks
+// that are accelerated
+const trimStreakInterval = 100 * time.Millisecond
+
+// trimMaxStreak limits the acceleration of the trim wheel
+const trimMaxStreak = 10
+
+// Trim turns the clicks of the multi panel pitch trim wheel into a trim
+// value between -1 (nose down) and 1 (nose up). Every click moves the value
+// by the sensitivity, by default 0.01. With acceleration, fast clicks in
+// the same direction move the value further:
+//   step = sensitivity * (1 + acceleration*n)
+// where n is the number of earlier clicks, up to 10, less than 100 ms
+// apart. The value is sent as a TrimEvent and to the sinks added with
+// AddSink on every change.
+type Trim struct {
+	mutex        sync.Mutex
+	value        float64
+	sensitivity  float64
+	acceleration float64
+	last         time.Time
+	lastDir      int
+	streak       int
+	sinks        []AxisSink
+	eventCh      chan TrimEvent
+}
+
+// NewTrim creates a new centered trim for the multi panel and starts
+// handling its trim wheel
+func NewTrim(panel *MultiPanel) *Trim {
+	t := &Trim{
+		sensitivity: 0.01,
+		eventCh:     make(chan TrimEvent, 16),
+	}
+	panel.AddSwitchHandler(t)
+	return t
+}
+
+// EventCh returns a channel for trim events
+func (t *Trim) EventCh() chan TrimEvent {
+	return t.eventCh
+}
+
+// SetSensitivity sets the change of the value for a single click
+func (t *Trim) SetSensitivity(s float64) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.sensitivity = s
+}
+
+// SetAcceleration sets how much fast clicks are accelerated. Zero turns off
+// acceleration.
+func (t *Trim) SetAcceleration(a float64) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.acceleration = a
+}
+
+// AddSink adds a sink that receives the trim value on every change. The
+// sink is called from the switch reader of the panel and should not block.
+func (t *Trim) AddSink(s AxisSink) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.sinks = append(t.sinks, s)
+}
+
+// Value returns the trim value
+func (t *Trim) Value() float64 {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	return t.value
+}
+
+// Set sets the trim value, for example when it has been changed by a
+// simulator. The value is limited to -1 to 1.
+func (t *Trim) Set(value float64) {
+	if value, sinks, ok := t.set(value); ok {
+		t.notify(value, sinks)
+	}
+}
+
+// Center sets the trim value to 0
+func (t *Trim) Center() {
+	t.Set(0)
+}
+
+// HandleSwitch handles the multi panel switch event s. It is called by the
+// panel, see AddSwitchHandler.
+func (t *Trim) HandleSwitch(s SwitchState) {
+	if !s.On || (s.Switch != TrimUp && s.Switch != TrimDown) {
+		return
+	}
+	dir := 1
+	if s.Switch == TrimDown {
+		dir = -1
+	}
+	if value, sinks, ok := t.click(dir, time.Now()); ok {
+		t.notify(value, sinks)
+	}
+}
+
+// set sets the value limited to -1 to 1. It returns the new value and the
+// sinks to notify, and false if the value did not change.
+func (t *Trim) set(value float64) (float64, []AxisSink, bool) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	return t.setValue(value)
+}
+
+// click moves the value one step in the direction dir for a click of the
+// trim wheel at the time now. It returns the new value and the sinks to
+// notify, and false if the value did not change.
+func (t *Trim) click(dir int, now time.Time) (float64, []AxisSink, bool) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	if dir == t.lastDir && now.Sub(t.last) < trimStreakInterval {
+		if t.streak < trimMaxStreak {
+			t.streak++
+		}
+	} else {
+		t.streak = 0
+	}
+	t.last = now
+	t.lastDir = dir
+	step := t.sensitivity * (1 + t.acceleration*float64(t.streak))
+	return t.setValue(t.value + float64(dir)*step)
+}
+
+// setValue sets the value limited to -1 to 1. It returns the new value and
+// the sinks to notify, and false if the value did not change. The trim
+// mutex must be held.
+func (t *Trim) setValue(value float64) (float64, []AxisSink, bool) {
+	switch {
+	case value < -1:
+		value = -1
+	case value > 1:
+		value = 1
+	}
+	if value == t.value {
+		return value, nil, false
+	}
+	t.value = value
+	return value, t.sinks, true
+}
+
+// notify sends the value to the event channel and the sinks. It is called
+// without holding the trim mutex, so that the sinks may use the trim.
+func (t *Trim) notify(value float64, sinks []AxisSink) {
+	select {
+	case t.eventCh <- TrimEvent{value}:
+	default:
+	}
+	for _, s := range sinks {
+		s.SetAxis(value)
+	}
+}
@@ -0,0 +1,66 @@
+package fpanels
+
+import (
+	"math"
+	"testing"
+)
+
+func TestTrim(t *testing.T) {
+	panel := newTestMultiPanel()
+	trim := NewTrim(panel)
+	var sunk []float64
+	trim.AddSink(AxisFunc(func(v float64) {
+		// The trim is not locked while the sinks are called
+		if trim.Value() != v {
+			t.Errorf("Sink got %v while the value is %v", v, trim.Value())
+		}
+		sunk = append(sunk, v)
+	}))
+	click := func(id SwitchID, n int) {
+		for i := 0; i < n; i++ {
+			panel.setSwitch(id, true)
+			panel.setSwitch(id, false)
+		}
+	}
+	steps := []struct {
+		name    string
+		do      func()
+		want    float64
+		changes int
+	}{
+		{"up", func() { click(TrimUp, 3) }, 0.03, 3},
+		{"down", func() { click(TrimDown, 1) }, 0.02, 1},
+		{"sensitivity", func() {
+			trim.SetSensitivity(0.1)
+			click(TrimDown, 2)
+		}, -0.18, 2},
+		{"acceleration", func() {
+			trim.SetAcceleration(1)
+			click(TrimUp, 3)
+		}, 0.42, 3},
+		{"direction change", func() { click(TrimDown, 1) }, 0.32, 1},
+		{"clamped", func() { click(TrimUp, 4) }, 1, 4},
+		{"at the limit", func() { click(TrimUp, 2) }, 1, 0},
+		{"set", func() { trim.Set(-2) }, -1, 1},
+		{"set unchanged", func() { trim.Set(-1) }, -1, 0},
+		{"center", func() { trim.Center() }, 0, 1},
+	}
+	for _, s := range steps {
+		for len(trim.EventCh()) > 0 {
+			<-trim.EventCh()
+		}
+		before := len(sunk)
+		s.do()
+		if v := trim.Value(); math.Abs(v-s.want) > 1e-9 {
+			t.Errorf("%s: value %v, want %v", s.name, v, s.want)
+		}
+		if n := len(sunk) - before; n != s.changes {
+			t.Errorf("%s: %d sink calls, want %d", s.name, n, s.changes)
+		} else if n > 0 && math.Abs(sunk[len(sunk)-1]-s.want) > 1e-9 {
+			t.Errorf("%s: sink got %v, want %v", s.name, sunk[len(sunk)-1], s.want)
+		}
+		if n := len(trim.EventCh()); n != s.changes {
+			t.Errorf("%s: %d events, want %d", s.name, n, s.changes)
+		}
+	}
+}